@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"lem-in/lemin"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// benchResult is how one solver did on one farm
+type benchResult struct {
+	Farm       string        `json:"farm"`
+	Solver     string        `json:"solver"`
+	Ants       int           `json:"ants"`
+	Turns      int           `json:"turns"`
+	Paths      int           `json:"paths"`
+	LowerBound int           `json:"lower_bound"`
+	Runtime    time.Duration `json:"runtime_ns"`
+	Memory     uint64        `json:"alloc_bytes"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// runBench solves every farm under a directory with every solver and
+// prints a comparison table, optionally writing the results as CSV or JSON
+func runBench(args []string) {
+	fset := flag.NewFlagSet("bench", flag.ExitOnError)
+	csvFile := fset.String("csv", "", "also write the results as CSV to this file")
+	jsonFile := fset.String("json", "", "also write the results as JSON to this file")
+	solverList := fset.String("solvers", strings.Join(lemin.Solvers(), ","), "comma separated solvers to compare")
+	fset.Parse(args)
+
+	if fset.NArg() != 1 {
+		fmt.Println("Usage: go run . bench [--csv=FILE] [--json=FILE] [--solvers=a,b] [directory]")
+		return
+	}
+	farms, err := findFarms(fset.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var results []benchResult
+	for _, farmFile := range farms {
+		results = append(results, benchFarm(farmFile, strings.Split(*solverList, ","))...)
+	}
+
+	printBenchTable(results)
+	if *csvFile != "" {
+		if err := writeBenchCSV(*csvFile, results); err != nil {
+			fmt.Println(err)
+		}
+	}
+	if *jsonFile != "" {
+		if err := writeBenchJSON(*jsonFile, results); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// findFarms returns every .txt file under dir, sorted
+func findFarms(dir string) ([]string, error) {
+	var farms []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".txt") {
+			farms = append(farms, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading farms: %v", err)
+	}
+	sort.Strings(farms)
+	return farms, nil
+}
+
+// benchFarm solves one farm with each solver, measuring time and memory
+func benchFarm(farmFile string, solvers []string) []benchResult {
+	farm, err := lemin.ParseFile(farmFile)
+	if err != nil {
+		return []benchResult{{Farm: farmFile, Error: err.Error()}}
+	}
+
+	results := make([]benchResult, 0, len(solvers))
+	for _, name := range solvers {
+		result := benchResult{Farm: farmFile, Solver: name, Ants: farm.Ants()}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		solution, err := lemin.Solve(farm, lemin.WithSolver(name))
+		result.Runtime = time.Since(start)
+		runtime.ReadMemStats(&after)
+		result.Memory = after.TotalAlloc - before.TotalAlloc
+
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Turns = len(solution.Turns)
+			result.Paths = len(solution.Paths)
+			result.LowerBound = solution.LowerBound
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func printBenchTable(results []benchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FARM\tSOLVER\tANTS\tTURNS\tBOUND\tPATHS\tTIME\tMEMORY\tERROR")
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t\t\t\t\t\t\t%s\n", r.Farm, r.Solver, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t\n",
+			r.Farm, r.Solver, r.Ants, r.Turns, r.LowerBound, r.Paths,
+			r.Runtime.Round(time.Microsecond), formatBytes(r.Memory))
+	}
+	w.Flush()
+}
+
+func writeBenchCSV(filename string, results []benchResult) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"farm", "solver", "ants", "turns", "lower_bound", "paths", "runtime_ns", "alloc_bytes", "error"})
+	for _, r := range results {
+		w.Write([]string{
+			r.Farm, r.Solver,
+			strconv.Itoa(r.Ants), strconv.Itoa(r.Turns), strconv.Itoa(r.LowerBound), strconv.Itoa(r.Paths),
+			strconv.FormatInt(r.Runtime.Nanoseconds(), 10), strconv.FormatUint(r.Memory, 10),
+			r.Error,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeBenchJSON(filename string, results []benchResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
+
+// formatBytes prints a byte count in the largest unit that keeps it above 1
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lem-in/lemin"
+	"os"
+	"strings"
+)
+
+// runGenerate writes a random farm to standard output or a file
+func runGenerate(args []string) {
+	fset := flag.NewFlagSet("generate", flag.ExitOnError)
+	rooms := fset.Int("rooms", 20, "number of rooms, including start and end")
+	density := fset.Float64("density", 0.2, "extra random links per room")
+	ants := fset.Int("ants", 10, "number of ants")
+	topology := fset.String("topology", "grid", "farm shape: "+strings.Join(lemin.Topologies(), ", "))
+	seed := fset.Int64("seed", 1, "random seed; the same seed gives the same farm")
+	output := fset.String("o", "", "write the farm to this file instead of standard output")
+	fset.Parse(args)
+
+	if fset.NArg() != 0 {
+		fmt.Println("Usage: go run . generate [--rooms=N] [--density=F] [--ants=N] [--topology=NAME] [--seed=N] [-o FILE]")
+		return
+	}
+	farm, err := lemin.Generate(lemin.GenerateOptions{
+		Rooms:    *rooms,
+		Density:  *density,
+		Ants:     *ants,
+		Topology: *topology,
+		Seed:     *seed,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		fmt.Print(farm)
+		return
+	}
+	if err := os.WriteFile(*output, []byte(farm), 0o644); err != nil {
+		fmt.Printf("error writing file: %v\n", err)
+		os.Exit(1)
+	}
+}
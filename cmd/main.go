@@ -1,27 +1,188 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"lem-in/internal"
+	"lem-in/lemin"
 	"os"
+	"slices"
+	"strings"
 )
 
+// formats the solution can be printed in with --format
+var formats = []string{"text", "json", "dot", "html", "gif", "png"}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: go run . [filename]")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "bench":
+			runBench(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "tui":
+			runTUI(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		}
+	}
+
+	seed := flag.Int64("seed", 0, "break ties between equal paths randomly using this seed (0 keeps a fixed order)")
+	check := flag.Bool("check", false, "report every problem in the farm instead of solving it")
+	solver := flag.String("solver", lemin.DefaultSolver, "path finding algorithm: "+strings.Join(lemin.Solvers(), ", "))
+	report := flag.Bool("report", false, "print the turn count, paths used, lower bound and optimality gap to stderr")
+	format := flag.String("format", "text", "output format: "+strings.Join(formats, ", "))
+	frames := flag.String("frames", "frames", "directory --format=png writes its frames to")
+	width := flag.Int("width", 640, "width of gif and png frames in pixels")
+	height := flag.Int("height", 480, "height of gif and png frames in pixels")
+	timeout := flag.Duration("timeout", 0, "stop solving after this long (0 for no limit)")
+	partial := flag.Bool("partial", false, "with --timeout, print the best solution found in time instead of failing")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: go run . [--seed=N] [--solver=NAME] [--format=text|json|dot|html|gif|png] [--timeout=D [--partial]] [--check] [--report] [filename|-]")
+		fmt.Println("       go run . verify [filename] [moves|-]")
+		fmt.Println("       go run . bench [--csv=FILE] [--json=FILE] [directory]")
+		fmt.Println("       go run . generate [--rooms=N] [--density=F] [--ants=N] [--topology=NAME] [--seed=N] [-o FILE]")
+		fmt.Println("       go run . tui [--solver=NAME] [--width=N] [--height=N] [filename]")
+		fmt.Println("       go run . serve [--addr=HOST:PORT] [--max-body=N] [--max-ants=N] [--timeout=D]")
+		return
+	}
+	if *check {
+		checkFarm(flag.Arg(0))
+		return
+	}
+	if !slices.Contains(formats, *format) {
+		fmt.Printf("unknown format %q, want one of %s\n", *format, strings.Join(formats, ", "))
+		return
+	}
+	farm, err := parseFarm(flag.Arg(0))
+	if err != nil {
+		printParseError(err)
 		return
 	}
-	farm := internal.NewAntFarm()
-	content, err := farm.ParseInput(os.Args[1])
+	if *seed != 0 {
+		farm.SetSeed(*seed)
+	}
+	opts := []lemin.Option{lemin.WithSolver(*solver), lemin.WithTimeout(*timeout)}
+	if *partial {
+		opts = append(opts, lemin.WithPartial())
+	}
+	solution, err := lemin.Solve(farm, opts...)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Print(content)
-	fmt.Println()
-	farm.EdmondsKarp()
-	moves := farm.SimulateAnts()
-	for _, move := range moves {
-		fmt.Println(move)
+	for _, warning := range solution.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	switch *format {
+	case "json":
+		if err := printJSON(farm, solution); err != nil {
+			fmt.Println(err)
+			return
+		}
+	case "dot":
+		if err := lemin.WriteDOT(os.Stdout, farm, solution); err != nil {
+			fmt.Println(err)
+			return
+		}
+	case "html":
+		if err := lemin.WriteHTML(os.Stdout, farm, solution); err != nil {
+			fmt.Println(err)
+			return
+		}
+	case "gif":
+		opts := lemin.FrameOptions{Width: *width, Height: *height, Delay: 50}
+		if err := lemin.WriteGIF(os.Stdout, farm, solution, opts); err != nil {
+			fmt.Println(err)
+			return
+		}
+	case "png":
+		opts := lemin.FrameOptions{Width: *width, Height: *height}
+		files, err := lemin.WritePNGs(*frames, farm, solution, opts)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("wrote %d frames to %s\n", len(files), *frames)
+	default:
+		fmt.Print(farm.Input())
+		fmt.Println()
+		for _, line := range solution.Lines() {
+			fmt.Println(line)
+		}
+	}
+	if *report {
+		fmt.Fprintf(os.Stderr, "turns: %d, paths: %d, lower bound: %d, gap: %d\n",
+			len(solution.Turns), len(solution.Paths), solution.LowerBound, solution.Gap())
+	}
+}
+
+// parseFarm reads the farm from the named file, or from standard input
+// when the name is -
+func parseFarm(name string) (*lemin.Farm, error) {
+	if name == "-" {
+		return lemin.Parse(os.Stdin)
+	}
+	return lemin.ParseFile(name)
+}
+
+// printJSON prints the farm and solution as a single JSON document
+func printJSON(farm *lemin.Farm, solution *lemin.Solution) error {
+	doc, err := lemin.NewDocument(farm, solution)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printParseError prints the message without a position, as before
+func printParseError(err error) {
+	var pe *lemin.ParseError
+	if errors.As(err, &pe) {
+		fmt.Println(pe.Legacy())
+		return
+	}
+	fmt.Println(err)
+}
+
+// checkFarm prints every problem found in the farm, one per line
+func checkFarm(name string) {
+	input := os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Printf("error opening file: %v\n", err)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+
+	problems, err := lemin.Diagnose(input)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(problems) == 0 {
+		fmt.Println("no problems found")
+		return
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
 	}
 }
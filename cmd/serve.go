@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lem-in/lemin"
+	"log"
+	"net/http"
+	"time"
+)
+
+// runServe answers solve, validate and verify requests over HTTP until
+// the process is stopped
+func runServe(args []string) {
+	fset := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fset.String("addr", ":8080", "address to listen on")
+	maxBody := fset.Int64("max-body", lemin.DefaultMaxBodyBytes, "largest request body accepted, in bytes")
+	timeout := fset.Duration("timeout", lemin.DefaultTimeout, "longest a request may take")
+	maxAnts := fset.Int("max-ants", lemin.DefaultMaxAnts, "most ants in a farm to solve or verify")
+	fset.Parse(args)
+
+	if fset.NArg() != 0 {
+		fmt.Println("Usage: go run . serve [--addr=HOST:PORT] [--max-body=N] [--max-ants=N] [--timeout=D]")
+		return
+	}
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           lemin.NewHandler(lemin.ServerOptions{MaxBodyBytes: *maxBody, Timeout: *timeout, MaxAnts: *maxAnts}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+		// Long enough for the handler's own timeout answer to get out
+		WriteTimeout: *timeout + 5*time.Second,
+	}
+	log.Printf("listening on %s", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"lem-in/lemin"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tuiHelp = "n/space/→ step, b/← back, p play, j N Enter jump to turn N, f N Enter follow ant N, f Enter stop following, q quit"
+
+// tui replays a solution in the terminal, redrawing the farm after every
+// key pressed
+type tui struct {
+	farm     *lemin.Farm
+	sim      *lemin.Simulation
+	out      io.Writer
+	grid     lemin.GridOptions
+	delay    time.Duration
+	buffered bool   // The terminal only passes keys on after Enter
+	command  rune   // j or f while a number is being typed for it, else 0
+	number   string // Digits typed so far for command
+	message  string // Shown under the farm until the next key
+}
+
+// runTUI draws the farm in the terminal and replays the solution turn by
+// turn under keyboard control
+func runTUI(args []string) {
+	fset := flag.NewFlagSet("tui", flag.ExitOnError)
+	solver := fset.String("solver", lemin.DefaultSolver, "path finding algorithm: "+strings.Join(lemin.Solvers(), ", "))
+	width := fset.Int("width", 80, "width of the farm drawing in characters")
+	height := fset.Int("height", 20, "height of the farm drawing in characters")
+	color := fset.Bool("color", true, "highlight paths and ants with ANSI colors")
+	delay := fset.Duration("delay", 500*time.Millisecond, "time between turns when playing")
+	fset.Parse(args)
+
+	if fset.NArg() != 1 {
+		fmt.Println("Usage: go run . tui [--solver=NAME] [--width=N] [--height=N] [--color=false] [--delay=D] [filename]")
+		return
+	}
+	if fset.Arg(0) == "-" {
+		fmt.Println("tui reads keys from standard input, so the farm has to come from a file")
+		return
+	}
+	farm, err := parseFarm(fset.Arg(0))
+	if err != nil {
+		printParseError(err)
+		return
+	}
+	solution, err := lemin.Solve(farm, lemin.WithSolver(*solver))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	sim, err := farm.Simulate(solution)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	t := &tui{
+		farm:  farm,
+		sim:   sim,
+		out:   os.Stdout,
+		grid:  lemin.GridOptions{Width: *width, Height: *height, Color: *color},
+		delay: *delay,
+	}
+	t.message = tuiHelp
+	if restore, err := singleKeys(); err != nil {
+		// Not a terminal stty can change, so keys still need Enter
+		t.buffered = true
+		t.message = tuiHelp + "; keys take effect after Enter"
+	} else {
+		defer restore()
+	}
+	t.run(os.Stdin)
+}
+
+// singleKeys switches the terminal on standard input to passing on every
+// key as it is pressed, without echoing it, and returns a function that
+// puts the terminal back the way it was
+func singleKeys() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	// Ctrl-C arrives as a key too, so that quitting restores the terminal
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1", "time", "0"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(saved)) }, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// Keys read from the terminal besides the characters typed
+const (
+	keyEnter     = '\n'
+	keyBackspace = 127
+	keyEscape    = 27
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+)
+
+// readKeys sends every key read from in, with the arrow keys turned into
+// the n and b they stand for, and closes the channel at the end of input
+func readKeys(in io.Reader, keys chan<- rune) {
+	defer close(keys)
+	r := bufio.NewReader(in)
+	for {
+		key, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		switch key {
+		case '\r':
+			key = keyEnter
+		case '\b':
+			key = keyBackspace
+		case keyEscape:
+			// Arrow keys come as ESC [ C and ESC [ D
+			if r.Buffered() >= 2 {
+				seq := make([]byte, 2)
+				io.ReadFull(r, seq)
+				switch string(seq) {
+				case "[C":
+					key = 'n'
+				case "[D":
+					key = 'b'
+				}
+			}
+		}
+		keys <- key
+	}
+}
+
+// run handles keys until q or the end of the input. Keys are read in the
+// background so that any key stops playback
+func (t *tui) run(in io.Reader) {
+	keys := make(chan rune)
+	go readKeys(in, keys)
+
+	for {
+		t.draw()
+		key, ok := <-keys
+		if !ok || key == keyCtrlC || key == keyCtrlD {
+			fmt.Fprintln(t.out)
+			return
+		}
+		t.message = ""
+		if t.command != 0 {
+			t.typeNumber(key)
+			continue
+		}
+
+		switch {
+		case key == 'n' || key == ' ' || key == keyEnter && !t.buffered:
+			if t.sim.Done() {
+				t.message = "last turn reached"
+			}
+			t.sim.Step()
+		case key == 'b':
+			t.sim.Seek(t.sim.Turn() - 1)
+		case key == 'p':
+			if !t.play(keys) {
+				fmt.Fprintln(t.out)
+				return
+			}
+		case key == 'j' || key == 'f':
+			t.command, t.number = key, ""
+		case key >= '0' && key <= '9':
+			// A bare number jumps to that turn
+			t.command, t.number = 'j', string(key)
+		case key == 'q':
+			fmt.Fprintln(t.out)
+			return
+		case key == keyEnter:
+			// The end of a buffered line
+		default:
+			t.message = "unknown key " + strconv.QuoteRune(key) + "; " + tuiHelp
+		}
+	}
+}
+
+// typeNumber adds a key to the number being typed for j or f, and carries
+// out the command on Enter. Escape or any other key cancels it
+func (t *tui) typeNumber(key rune) {
+	switch {
+	case key >= '0' && key <= '9':
+		t.number += string(key)
+		return
+	case key == keyBackspace:
+		if t.number != "" {
+			t.number = t.number[:len(t.number)-1]
+		}
+		return
+	}
+
+	command := t.command
+	t.command = 0
+	if key != keyEnter {
+		return
+	}
+	switch {
+	case command == 'f' && t.number == "":
+		t.grid.Follow = 0
+	case command == 'f':
+		if ant, ok := t.parseNumber("f", 1, t.farm.Ants()); ok {
+			t.grid.Follow = ant
+		}
+	default:
+		if turn, ok := t.parseNumber("j", 0, t.sim.Turns()); ok {
+			t.sim.Seek(turn)
+		}
+	}
+}
+
+// play steps through the turns until the last one or any key, and
+// reports false when the input ended
+func (t *tui) play(keys <-chan rune) bool {
+	if t.sim.Done() {
+		t.sim.Seek(0)
+	}
+	ticker := time.NewTicker(t.delay)
+	defer ticker.Stop()
+	for !t.sim.Done() {
+		t.message = "playing, press any key to stop"
+		t.draw()
+		select {
+		case key, ok := <-keys:
+			t.message = ""
+			return ok && key != keyCtrlC && key != keyCtrlD
+		case <-ticker.C:
+			t.sim.Step()
+		}
+	}
+	t.message = ""
+	return true
+}
+
+// parseNumber reads the number typed for a command, between lo and hi
+func (t *tui) parseNumber(command string, lo, hi int) (int, bool) {
+	n, err := strconv.Atoi(t.number)
+	if err != nil || n < lo || n > hi {
+		t.message = fmt.Sprintf("%s takes a number from %d to %d", command, lo, hi)
+		return 0, false
+	}
+	return n, true
+}
+
+// draw clears the terminal and prints the farm, the turn and the prompt
+func (t *tui) draw() {
+	var sb strings.Builder
+	sb.WriteString("\033[H\033[2J")
+	for _, line := range t.sim.DrawGrid(t.grid) {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	waiting, arrived := 0, 0
+	for _, ant := range t.sim.Ants() {
+		if ant.Room == t.farm.Start().Name && ant.Heading == "" {
+			waiting++
+		} else if ant.Arrived {
+			arrived++
+		}
+	}
+	fmt.Fprintf(&sb, "\nTurn %d of %d: %d waiting, %d on the way, %d arrived\n",
+		t.sim.Turn(), t.sim.Turns(), waiting, t.farm.Ants()-waiting-arrived, arrived)
+	if moves := t.sim.Moves(t.sim.Turn()); len(moves) > 0 {
+		names := make([]string, len(moves))
+		for i, m := range moves {
+			names[i] = m.String()
+		}
+		fmt.Fprintf(&sb, "Moves: %s\n", strings.Join(names, " "))
+	}
+	if ant, ok := t.sim.Ant(t.grid.Follow); ok {
+		where := "in " + ant.Room
+		if ant.Heading != "" {
+			where = fmt.Sprintf("%.0f%% of the way from %s to %s", 100*ant.Through, ant.Room, ant.Heading)
+		}
+		fmt.Fprintf(&sb, "Ant %d is %s on path %d (%s)", ant.Ant, where, ant.Path+1, strings.Join(ant.Route, "-"))
+		switch {
+		case ant.Arrived:
+			fmt.Fprintf(&sb, ", arrived on turn %d", ant.Arrival)
+		case ant.Arrival > 0:
+			fmt.Fprintf(&sb, ", arriving on turn %d", ant.Arrival)
+		}
+		sb.WriteString("\n")
+	}
+	if t.message != "" {
+		fmt.Fprintln(&sb, t.message)
+	}
+	switch t.command {
+	case 'j':
+		fmt.Fprintf(&sb, "Jump to turn: %s", t.number)
+	case 'f':
+		fmt.Fprintf(&sb, "Follow ant (Enter alone stops following): %s", t.number)
+	}
+	io.WriteString(t.out, sb.String())
+}
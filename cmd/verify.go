@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"lem-in/lemin"
+	"os"
+	"strings"
+)
+
+// runVerify checks a list of moves against a farm and exits with status 1
+// when they break a rule
+func runVerify(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: go run . verify [filename] [moves|-]")
+		return
+	}
+	farm, err := parseFarm(args[0])
+	if err != nil {
+		printParseError(err)
+		os.Exit(1)
+	}
+	lines, err := readMoves(args[1])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if err := lemin.Verify(farm, lines); err != nil {
+		fmt.Printf("INVALID: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("OK: %d ants in %d turns\n", farm.Ants(), len(lines))
+}
+
+// readMoves reads one line of moves per turn from the named file, or from
+// standard input when the name is -. When the input is a full lem-in output
+// the farm description before the first blank line is skipped. Blank lines
+// after it are turns where every ant on the way is inside a long tunnel
+func readMoves(name string) ([]string, error) {
+	var input io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var all []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		all = append(all, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading moves: %v", err)
+	}
+
+	// A farm description starts with the number of ants, moves with L
+	if len(all) > 0 && all[0] != "" && !strings.HasPrefix(all[0], "L") {
+		for i, line := range all {
+			if line == "" {
+				all = all[i+1:]
+				break
+			}
+		}
+	}
+	for len(all) > 0 && all[len(all)-1] == "" {
+		all = all[:len(all)-1]
+	}
+	return all, nil
+}
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"html/template"
+	"io"
+	"math"
+)
+
+// animation is everything the page needs to draw the farm and move the
+// ants, in SVG units
+type animation struct {
+	Width  float64    `json:"width"`
+	Height float64    `json:"height"`
+	Radius float64    `json:"radius"`
+	Labels bool       `json:"labels"`
+	Turns  int        `json:"turns"`
+	Start  int        `json:"start"`
+	End    int        `json:"end"`
+	Rooms  []animRoom `json:"rooms"`
+	Links  []animLink `json:"links"`
+	Ants   []animAnt  `json:"ants"`
+}
+
+type animRoom struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+type animLink struct {
+	A     int    `json:"a"`
+	B     int    `json:"b"`
+	Color string `json:"color,omitempty"` // Color of the path using it, if any
+}
+
+type animAnt struct {
+	Color   string    `json:"color"`
+	Rooms   []int     `json:"rooms"`             // Room it is in after each turn, from turn 0
+	Heading []int     `json:"heading,omitempty"` // Room it is going to inside a tunnel after each turn, -1 for none
+	Through []float64 `json:"through,omitempty"` // How far through that tunnel it is
+}
+
+// WriteHTML writes a self-contained HTML page animating the simulation
+// over the farm's room coordinates, with play/pause, single steps and a
+// slider to scrub through the turns. It needs nothing but a browser
+func (af *AntFarm) WriteHTML(w io.Writer, sim *Simulation) error {
+	rooms := af.Rooms()
+	index := make(map[string]int, len(rooms))
+	minX, minY := math.MaxInt, math.MaxInt
+	maxX, maxY := math.MinInt, math.MinInt
+	for i, room := range rooms {
+		index[room.name] = i
+		minX, maxX = min(minX, room.x), max(maxX, room.x)
+		minY, maxY = min(minY, room.y), max(maxY, room.y)
+	}
+
+	// Fit the farm in about 800 units without spreading small farms out
+	// too far, leaving a margin for the labels
+	const size, margin = 800.0, 40.0
+	span := float64(max(maxX-minX, maxY-minY, 1))
+	scale := math.Min(size/span, 80)
+	data := animation{
+		Width:  float64(maxX-minX)*scale + 2*margin,
+		Height: float64(maxY-minY)*scale + 2*margin,
+		Radius: math.Max(2, math.Min(14, scale/4)),
+		Labels: len(rooms) <= 200,
+		Turns:  sim.Turns(),
+		Start:  index[af.startRoom.name],
+		End:    index[af.endRoom.name],
+		Rooms:  make([]animRoom, len(rooms)),
+		Links:  make([]animLink, len(af.links)),
+		Ants:   make([]animAnt, 0, af.numAnts),
+	}
+	for i, room := range rooms {
+		data.Rooms[i] = animRoom{
+			Name: room.name,
+			X:    float64(room.x-minX)*scale + margin,
+			Y:    float64(room.y-minY)*scale + margin,
+		}
+	}
+
+	linkColors := make(map[[2]string]string)
+	for i, path := range sim.Paths() {
+		for k := 1; k < len(path); k++ {
+			if key := linkKey(path[k-1], path[k]); linkColors[key] == "" {
+				linkColors[key] = PathColor(i)
+			}
+		}
+	}
+	for i, link := range af.links {
+		data.Links[i] = animLink{A: index[link[0]], B: index[link[1]], Color: linkColors[linkKey(link[0], link[1])]}
+	}
+
+	// Play every turn to record where each ant is, then put the
+	// simulation back where it was
+	played := sim.Turn()
+	for turn := 0; turn <= sim.Turns(); turn++ {
+		sim.Seek(turn)
+		for i, ant := range sim.Ants() {
+			if turn == 0 {
+				color := "#333333"
+				if ant.Path >= 0 {
+					color = PathColor(ant.Path)
+				}
+				data.Ants = append(data.Ants, animAnt{Color: color, Rooms: make([]int, 0, sim.Turns()+1)})
+			}
+			data.Ants[i].Rooms = append(data.Ants[i].Rooms, index[ant.Room])
+			// Only farms with long tunnels need to say where ants are
+			// inside them
+			if len(af.lengths) > 0 {
+				heading := -1
+				if ant.Heading != "" {
+					heading = index[ant.Heading]
+				}
+				data.Ants[i].Heading = append(data.Ants[i].Heading, heading)
+				data.Ants[i].Through = append(data.Ants[i].Through, ant.Through)
+			}
+		}
+	}
+	sim.Seek(played)
+
+	return animationPage.Execute(w, data)
+}
+
+var animationPage = template.Must(template.New("animation").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>lem-in</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+#controls { margin-bottom: 0.5em; display: flex; align-items: center; gap: 0.5em; }
+#turn { width: 30em; }
+svg { border: 1px solid #ddd; max-width: 100%; height: auto; }
+.label { font-size: 11px; fill: #555; pointer-events: none; }
+.count { font-size: 13px; font-weight: bold; pointer-events: none; }
+</style>
+</head>
+<body>
+<div id="controls">
+<button id="play">Play</button>
+<button id="prev">&lt;</button>
+<button id="next">&gt;</button>
+<input id="turn" type="range" min="0" value="0">
+<span id="status"></span>
+</div>
+<svg id="farm" xmlns="http://www.w3.org/2000/svg"></svg>
+<script>
+const data = {{.}};
+const NS = "http://www.w3.org/2000/svg";
+const svg = document.getElementById("farm");
+svg.setAttribute("viewBox", "0 0 " + data.width + " " + data.height);
+svg.setAttribute("width", data.width);
+svg.setAttribute("height", data.height);
+
+function add(tag, attrs, parent) {
+	const node = document.createElementNS(NS, tag);
+	for (const name in attrs) node.setAttribute(name, attrs[name]);
+	(parent || svg).appendChild(node);
+	return node;
+}
+
+for (const link of data.links) {
+	const a = data.rooms[link.a], b = data.rooms[link.b];
+	add("line", {x1: a.x, y1: a.y, x2: b.x, y2: b.y,
+		stroke: link.color || "#cccccc", "stroke-width": link.color ? 3 : 1});
+}
+data.rooms.forEach((room, i) => {
+	let fill = "#ffffff";
+	if (i === data.start) fill = "#b2df8a";
+	if (i === data.end) fill = "#fb9a99";
+	const circle = add("circle", {cx: room.x, cy: room.y, r: data.radius, fill: fill, stroke: "#777777"});
+	add("title", {}, circle).textContent = room.name;
+	if (data.labels || i === data.start || i === data.end) {
+		add("text", {x: room.x + data.radius + 2, y: room.y - data.radius - 2, class: "label"}).textContent = room.name;
+	}
+});
+
+// Ants waiting in the start room or done in the end room are only counted
+const counts = [data.start, data.end].map(i =>
+	add("text", {x: data.rooms[i].x - data.radius, y: data.rooms[i].y + 2.5 * data.radius + 10, class: "count"}));
+const ants = data.ants.map(ant =>
+	add("circle", {r: data.radius * 0.7, fill: ant.color, stroke: "#000000", "stroke-width": 0.5}));
+
+const slider = document.getElementById("turn");
+const status = document.getElementById("status");
+const play = document.getElementById("play");
+slider.max = data.turns;
+
+let time = 0, playing = false, last = null;
+const turnsPerSecond = 1.5;
+
+// place is where an ant is after a turn, part way along a tunnel when
+// it is inside one
+function place(ant, turn) {
+	const a = data.rooms[ant.rooms[turn]];
+	if (!ant.heading || ant.heading[turn] < 0) return a;
+	const b = data.rooms[ant.heading[turn]], t = ant.through[turn];
+	return {x: a.x + (b.x - a.x) * t, y: a.y + (b.y - a.y) * t};
+}
+
+function inside(ant, turn) {
+	return ant.heading !== undefined && ant.heading[turn] >= 0;
+}
+
+function draw() {
+	const turn = Math.min(Math.floor(time), data.turns);
+	const next = Math.min(turn + 1, data.turns);
+	const step = time - turn;
+	let waiting = 0, done = 0;
+	data.ants.forEach((ant, i) => {
+		const from = ant.rooms[turn], to = ant.rooms[next];
+		const moving = step > 0 && (from !== to || inside(ant, turn) || inside(ant, next));
+		const still = !moving && !inside(ant, turn);
+		if (still && from === data.start) waiting++;
+		if (still && from === data.end) done++;
+		if (still && (from === data.start || from === data.end)) {
+			ants[i].setAttribute("visibility", "hidden");
+			return;
+		}
+		const a = place(ant, turn), b = moving ? place(ant, next) : a;
+		ants[i].setAttribute("visibility", "visible");
+		ants[i].setAttribute("cx", a.x + (b.x - a.x) * step);
+		ants[i].setAttribute("cy", a.y + (b.y - a.y) * step);
+	});
+	counts[0].textContent = waiting ? waiting + " waiting" : "";
+	counts[1].textContent = done ? done + " arrived" : "";
+	slider.value = turn;
+	status.textContent = "Turn " + turn + " of " + data.turns;
+}
+
+function frame(now) {
+	if (!playing) return;
+	if (last !== null) time += (now - last) / 1000 * turnsPerSecond;
+	last = now;
+	if (time >= data.turns) {
+		time = data.turns;
+		pause();
+	}
+	draw();
+	if (playing) requestAnimationFrame(frame);
+}
+
+function pause() {
+	playing = false;
+	play.textContent = "Play";
+}
+
+play.onclick = () => {
+	if (playing) {
+		pause();
+		return;
+	}
+	if (time >= data.turns) time = 0;
+	playing = true;
+	last = null;
+	play.textContent = "Pause";
+	requestAnimationFrame(frame);
+};
+function jump(turn) {
+	pause();
+	time = Math.max(0, Math.min(turn, data.turns));
+	draw();
+}
+document.getElementById("prev").onclick = () => jump(Math.ceil(time) - 1);
+document.getElementById("next").onclick = () => jump(Math.floor(time) + 1);
+slider.oninput = () => jump(Number(slider.value));
+draw();
+</script>
+</body>
+</html>
+`))
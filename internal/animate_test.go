@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	af, sim := simulateValidFarm(t)
+	sim.Seek(2)
+
+	var sb strings.Builder
+	if err := af.WriteHTML(&sb, sim); err != nil {
+		t.Fatalf("WriteHTML() unexpected error: %v", err)
+	}
+	page := sb.String()
+	if sim.Turn() != 2 {
+		t.Errorf("WriteHTML() left the simulation at turn %d, want 2", sim.Turn())
+	}
+
+	// The data the script animates is the JSON after "const data ="
+	_, rest, ok := strings.Cut(page, "const data = ")
+	if !ok {
+		t.Fatalf("WriteHTML() page has no data:\n%s", page)
+	}
+	var data animation
+	if err := json.NewDecoder(strings.NewReader(rest)).Decode(&data); err != nil {
+		t.Fatalf("WriteHTML() data is not JSON: %v", err)
+	}
+	if data.Turns != 6 || len(data.Rooms) != 4 || len(data.Links) != 3 || len(data.Ants) != 4 {
+		t.Errorf("WriteHTML() data has %d turns, %d rooms, %d links and %d ants, want 6, 4, 3 and 4",
+			data.Turns, len(data.Rooms), len(data.Links), len(data.Ants))
+	}
+	// Rooms are numbered in the order they were parsed: start room1 room2 end
+	if got := data.Ants[1].Rooms; len(got) != 7 || got[0] != 0 || got[1] != 0 || got[2] != 1 || got[4] != 3 || got[6] != 3 {
+		t.Errorf("ant 2 goes through rooms %v, want [0 0 1 2 3 3 3]", got)
+	}
+	for _, want := range []string{`id="play"`, `id="turn"`, "<svg"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("WriteHTML() page is missing %s", want)
+		}
+	}
+}
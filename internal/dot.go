@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pathColors are given to paths in order, starting over after the last
+var pathColors = []string{
+	"#e41a1c", "#377eb8", "#4daf4a", "#984ea3", "#ff7f00",
+	"#a65628", "#f781bf", "#17becf", "#bcbd22", "#666666",
+}
+
+// PathColor returns the color used to draw path i
+func PathColor(i int) string {
+	return pathColors[i%len(pathColors)]
+}
+
+// WriteDOT writes the farm as a Graphviz graph, every room pinned at its
+// coordinates, with the start and end rooms marked and each path drawn
+// in its own color, labelled with the number of ants sent down it.
+// Tunnels taking more than one turn are labelled with how many.
+// Render it with neato or fdp, e.g. neato -Tsvg farm.dot -o farm.svg
+func (af *AntFarm) WriteDOT(w io.Writer, paths [][]string, ants []int) error {
+	// Colors of the paths going through each room and each tunnel
+	roomColors := make(map[string][]string)
+	linkColors := make(map[[2]string][]string)
+	linkLabels := make(map[[2]string]string)
+	for i, path := range paths {
+		color := PathColor(i)
+		for k, room := range path {
+			if k > 0 && k < len(path)-1 {
+				roomColors[room] = append(roomColors[room], color)
+			}
+			if k == 0 {
+				continue
+			}
+			key := linkKey(path[k-1], room)
+			linkColors[key] = append(linkColors[key], color)
+			if k == 1 && i < len(ants) {
+				linkLabels[key] = plural(ants[i], "ant")
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("graph farm {\n")
+	sb.WriteString("\tlayout=neato\n")
+	sb.WriteString("\tinputscale=1\n")
+	fmt.Fprintf(&sb, "\tlabel=%s\n", dotQuote(plural(af.numAnts, "ant")+", "+plural(len(paths), "path")))
+	sb.WriteString("\tnode [shape=circle, fontsize=10]\n")
+	sb.WriteString("\tedge [color=\"#bbbbbb\"]\n")
+
+	for _, room := range af.Rooms() {
+		// Graphviz puts y upwards, farms put it downwards
+		attrs := []string{fmt.Sprintf("pos=\"%d,%d!\"", room.x, -room.y)}
+		switch {
+		case room == af.startRoom:
+			attrs = append(attrs, "shape=doublecircle", "style=filled", "fillcolor=\"#b2df8a\"", "xlabel=start")
+		case room == af.endRoom:
+			attrs = append(attrs, "shape=doublecircle", "style=filled", "fillcolor=\"#fb9a99\"", "xlabel=end")
+		case len(roomColors[room.name]) > 0:
+			attrs = append(attrs, "penwidth=2", "color="+dotQuote(strings.Join(roomColors[room.name], ":")))
+		}
+		fmt.Fprintf(&sb, "\t%s [%s]\n", dotQuote(room.name), strings.Join(attrs, ", "))
+	}
+
+	for _, link := range af.links {
+		key := linkKey(link[0], link[1])
+		attrs := make([]string, 0)
+		if colors := linkColors[key]; len(colors) > 0 {
+			// A tunnel used by several paths is drawn as parallel lines
+			attrs = append(attrs, "penwidth=3", "color="+dotQuote(strings.Join(colors, ":")))
+		}
+		label := linkLabels[key]
+		if n := af.LinkLength(link[0], link[1]); n > 1 {
+			label = strings.TrimPrefix(label+", "+plural(n, "turn"), ", ")
+		}
+		if label != "" {
+			attrs = append(attrs, "label="+dotQuote(label), "fontsize=9")
+		}
+		fmt.Fprintf(&sb, "\t%s -- %s", dotQuote(link[0]), dotQuote(link[1]))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// plural formats a count of things, e.g. 1 ant or 3 ants
+func plural(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, thing)
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// linkKey names a tunnel the same way whichever end it is given from
+func linkKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// dotQuote quotes a string for DOT, where only quotes and backslashes
+// need escaping
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	af := NewAntFarm()
+	if _, err := af.ParseInput("testfarms/validfarm.txt"); err != nil {
+		t.Fatalf("ParseInput() unexpected error: %v", err)
+	}
+	af.EdmondsKarp()
+
+	var sb strings.Builder
+	if err := af.WriteDOT(&sb, af.paths, []int{4}); err != nil {
+		t.Fatalf("WriteDOT() unexpected error: %v", err)
+	}
+	dot := sb.String()
+	for _, want := range []string{
+		"graph farm {",
+		`label="4 ants, 1 path"`,
+		`"start" [pos="0,0!", shape=doublecircle`,
+		`"end" [pos="3,-3!", shape=doublecircle`,
+		`"room1" [pos="1,-1!", penwidth=2, color="#e41a1c"]`,
+		`"start" -- "room1" [penwidth=3, color="#e41a1c", label="4 ants"`,
+		`"room2" -- "end" [penwidth=3, color="#e41a1c"]`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("WriteDOT() output is missing %q:\n%s", want, dot)
+		}
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("WriteDOT() output is not closed:\n%s", dot)
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	tests := map[string]string{
+		"room":    `"room"`,
+		`a"b`:     `"a\"b"`,
+		`back\`:   `"back\\"`,
+		"#e41a1c": `"#e41a1c"`,
+	}
+	for in, want := range tests {
+		if got := dotQuote(in); got != want {
+			t.Errorf("dotQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
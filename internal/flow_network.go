@@ -0,0 +1,109 @@
+package internal
+
+import "context"
+
+// flowNetwork is an integer-indexed graph for max flow on the networks
+// the exact solver builds. Edges are stored in pairs so the reverse of
+// edge e is e^1
+type flowNetwork struct {
+	head  []int // First edge leaving each node, -1 for none
+	next  []int // Next edge leaving the same node
+	to    []int // Node each edge points to
+	cap   []int // Remaining capacity of each edge
+	level []int // BFS level of each node in the current phase
+	iter  []int // Next edge to try from each node in the current phase
+}
+
+func newFlowNetwork() *flowNetwork {
+	return &flowNetwork{}
+}
+
+// addNode adds a node and returns its index
+func (g *flowNetwork) addNode() int {
+	g.head = append(g.head, -1)
+	return len(g.head) - 1
+}
+
+// addEdge adds an edge from u to v and its zero capacity reverse
+func (g *flowNetwork) addEdge(u, v, capacity int) {
+	g.to = append(g.to, v, u)
+	g.cap = append(g.cap, capacity, 0)
+	g.next = append(g.next, g.head[u], g.head[v])
+	g.head[u] = len(g.to) - 2
+	g.head[v] = len(g.to) - 1
+}
+
+// maxFlow pushes as much flow as possible from source to sink using
+// Dinic's algorithm and returns the amount. It stops early, with less than
+// the maximum, once ctx is done
+func (g *flowNetwork) maxFlow(ctx context.Context, source, sink int) int {
+	total := 0
+	g.level = make([]int, len(g.head))
+	g.iter = make([]int, len(g.head))
+	for ctx.Err() == nil && g.buildLevels(source, sink) {
+		copy(g.iter, g.head)
+		for ctx.Err() == nil {
+			pushed := g.push(source, sink, int(^uint(0)>>1))
+			if pushed == 0 {
+				break
+			}
+			total += pushed
+		}
+	}
+	return total
+}
+
+// buildLevels labels nodes by BFS distance from source over edges with
+// capacity left, and reports whether sink can still be reached
+func (g *flowNetwork) buildLevels(source, sink int) bool {
+	for i := range g.level {
+		g.level[i] = -1
+	}
+	g.level[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for e := g.head[u]; e >= 0; e = g.next[e] {
+			if v := g.to[e]; g.cap[e] > 0 && g.level[v] < 0 {
+				g.level[v] = g.level[u] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	return g.level[sink] >= 0
+}
+
+// push sends up to limit units from u to sink along increasing levels
+func (g *flowNetwork) push(u, sink, limit int) int {
+	if u == sink {
+		return limit
+	}
+	for ; g.iter[u] >= 0; g.iter[u] = g.next[g.iter[u]] {
+		e := g.iter[u]
+		v := g.to[e]
+		if g.cap[e] <= 0 || g.level[v] != g.level[u]+1 {
+			continue
+		}
+		if pushed := g.push(v, sink, min(limit, g.cap[e])); pushed > 0 {
+			g.cap[e] -= pushed
+			g.cap[e^1] += pushed
+			return pushed
+		}
+	}
+	return 0
+}
+
+// takeFlow removes one unit of flow from an edge leaving u and returns
+// that edge, so calling it repeatedly walks flow paths one unit at a time
+func (g *flowNetwork) takeFlow(u int) int {
+	for e := g.head[u]; e >= 0; e = g.next[e] {
+		// Forward edges have even indexes and carry as much flow as their
+		// reverse has capacity
+		if e%2 == 0 && g.cap[e^1] > 0 {
+			g.cap[e^1]--
+			return e
+		}
+	}
+	return -1
+}
@@ -0,0 +1,288 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// FrameOptions controls the size and speed of the frames DrawFrame draws
+type FrameOptions struct {
+	Width, Height int // Size of each frame in pixels
+	Delay         int // Time each turn is shown in a GIF, in 100ths of a second
+}
+
+// Palette indexes, the path colors following on from framePaths
+const (
+	frameBackground = iota
+	frameTunnel
+	frameOutline
+	frameRoom
+	frameStart
+	frameEnd
+	frameText
+	frameAnt // Ants on no known path
+	framePaths
+)
+
+var framePalette = func() color.Palette {
+	palette := color.Palette{
+		color.RGBA{0xff, 0xff, 0xff, 0xff},
+		color.RGBA{0xcc, 0xcc, 0xcc, 0xff},
+		color.RGBA{0x77, 0x77, 0x77, 0xff},
+		color.RGBA{0xf4, 0xf4, 0xf4, 0xff},
+		color.RGBA{0xb2, 0xdf, 0x8a, 0xff},
+		color.RGBA{0xfb, 0x9a, 0x99, 0xff},
+		color.RGBA{0x00, 0x00, 0x00, 0xff},
+		color.RGBA{0x33, 0x33, 0x33, 0xff},
+	}
+	for _, hex := range pathColors {
+		v, _ := strconv.ParseUint(hex[1:], 16, 32)
+		palette = append(palette, color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff})
+	}
+	return palette
+}()
+
+// DrawFrame draws the farm as it is at the simulation's current turn:
+// rooms at their coordinates, tunnels, paths in their colors and every
+// ant on the way as a dot with its number. The start and end rooms are
+// labelled with how many ants are in them, and the turn is in the corner
+func (af *AntFarm) DrawFrame(sim *Simulation, opts FrameOptions) *image.Paletted {
+	width, height := max(opts.Width, 64), max(opts.Height, 64)
+	img := image.NewPaletted(image.Rect(0, 0, width, height), framePalette)
+
+	rooms := af.Rooms()
+	minX, maxX, minY, maxY := rooms[0].x, rooms[0].x, rooms[0].y, rooms[0].y
+	for _, room := range rooms {
+		minX, maxX = min(minX, room.x), max(maxX, room.x)
+		minY, maxY = min(minY, room.y), max(maxY, room.y)
+	}
+	// Keep the farm's proportions, centered, with room for the labels
+	const margin = 30.0
+	scale := math.Min(
+		(float64(width)-2*margin)/float64(max(maxX-minX, 1)),
+		(float64(height)-2*margin)/float64(max(maxY-minY, 1)))
+	offsetX := (float64(width) - float64(maxX-minX)*scale) / 2
+	offsetY := (float64(height) - float64(maxY-minY)*scale) / 2
+	points := make(map[string]image.Point, len(rooms))
+	for _, room := range rooms {
+		points[room.name] = image.Point{
+			X: int(math.Round(float64(room.x-minX)*scale + offsetX)),
+			Y: int(math.Round(float64(room.y-minY)*scale + offsetY)),
+		}
+	}
+	radius := int(math.Max(3, math.Min(12, scale/4)))
+
+	pathColor := make(map[[2]string]uint8)
+	for i, path := range sim.Paths() {
+		for k := 1; k < len(path); k++ {
+			if key := linkKey(path[k-1], path[k]); pathColor[key] == 0 {
+				pathColor[key] = uint8(framePaths + i%len(pathColors))
+			}
+		}
+	}
+	// Paths go over plain tunnels where they cross
+	for _, link := range af.links {
+		if pathColor[linkKey(link[0], link[1])] == 0 {
+			drawSegment(img, points[link[0]], points[link[1]], 0, frameTunnel)
+		}
+	}
+	for _, link := range af.links {
+		if c := pathColor[linkKey(link[0], link[1])]; c != 0 {
+			drawSegment(img, points[link[0]], points[link[1]], 1, c)
+		}
+	}
+
+	for _, room := range rooms {
+		fill := uint8(frameRoom)
+		switch room {
+		case af.startRoom:
+			fill = frameStart
+		case af.endRoom:
+			fill = frameEnd
+		}
+		fillCircle(img, points[room.name], radius+1, frameOutline)
+		fillCircle(img, points[room.name], radius, fill)
+	}
+
+	waiting, arrived := 0, 0
+	antRadius := max(radius, 8)
+	for _, ant := range sim.Ants() {
+		switch {
+		case ant.Heading != "":
+			// Inside a tunnel, so in neither room
+		case ant.Room == af.startRoom.name:
+			waiting++
+			continue
+		case ant.Room == af.endRoom.name:
+			arrived++
+			continue
+		}
+		c := uint8(frameAnt)
+		if ant.Path >= 0 {
+			c = uint8(framePaths + ant.Path%len(pathColors))
+		}
+		p := points[ant.Room]
+		if ant.Heading != "" {
+			// Part way along the tunnel it is inside
+			q := points[ant.Heading]
+			p.X += int(math.Round(float64(q.X-p.X) * ant.Through))
+			p.Y += int(math.Round(float64(q.Y-p.Y) * ant.Through))
+		}
+		fillCircle(img, p, antRadius, c)
+		// Double size digits when the number fits inside the dot
+		number := strconv.Itoa(ant.Ant)
+		digitScale := 1
+		if (4*len(number)-1)*2 <= 2*antRadius-2 {
+			digitScale = 2
+		}
+		drawText(img, p, number, digitScale, frameBackground)
+	}
+
+	textScale := 2
+	for _, label := range []struct {
+		room  *Room
+		count int
+	}{{af.startRoom, waiting}, {af.endRoom, arrived}} {
+		if label.count > 0 {
+			p := points[label.room.name].Add(image.Point{Y: radius + 8})
+			drawText(img, p, strconv.Itoa(label.count), textScale, frameText)
+		}
+	}
+	turn := fmt.Sprintf("%d/%d", sim.Turn(), sim.Turns())
+	drawText(img, image.Point{X: 8 + (4*len(turn)-1)*textScale/2, Y: 8 + 5*textScale/2}, turn, textScale, frameText)
+	return img
+}
+
+// WriteGIF writes an animated GIF with one frame per turn, from before
+// the first move to after the last, which is held a little longer
+func (af *AntFarm) WriteGIF(w io.Writer, sim *Simulation, opts FrameOptions) error {
+	anim := &gif.GIF{}
+	played := sim.Turn()
+	for turn := 0; turn <= sim.Turns(); turn++ {
+		sim.Seek(turn)
+		anim.Image = append(anim.Image, af.DrawFrame(sim, opts))
+		anim.Delay = append(anim.Delay, opts.Delay)
+	}
+	sim.Seek(played)
+	anim.Delay[len(anim.Delay)-1] = 3 * opts.Delay
+	return gif.EncodeAll(w, anim)
+}
+
+// WritePNGs writes one PNG per turn into dir, named frame-000.png from
+// before the first move onwards, and returns the file names
+func (af *AntFarm) WritePNGs(dir string, sim *Simulation, opts FrameOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating directory: %v", err)
+	}
+	played := sim.Turn()
+	defer sim.Seek(played)
+
+	digits := max(3, len(strconv.Itoa(sim.Turns())))
+	files := make([]string, 0, sim.Turns()+1)
+	for turn := 0; turn <= sim.Turns(); turn++ {
+		sim.Seek(turn)
+		name := filepath.Join(dir, fmt.Sprintf("frame-%0*d.png", digits, turn))
+		file, err := os.Create(name)
+		if err != nil {
+			return files, fmt.Errorf("error creating file: %v", err)
+		}
+		err = png.Encode(file, af.DrawFrame(sim, opts))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return files, fmt.Errorf("error writing %s: %v", name, err)
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// drawSegment draws a line from a to b, thick pixels either side of it
+func drawSegment(img *image.Paletted, a, b image.Point, thick int, c uint8) {
+	x, y := a.X, a.Y
+	dx, dy := abs(b.X-x), -abs(b.Y-y)
+	sx, sy := sign(b.X-x), sign(b.Y-y)
+	e := dx + dy
+	for {
+		for ox := -thick; ox <= thick; ox++ {
+			for oy := -thick; oy <= thick; oy++ {
+				setPixel(img, x+ox, y+oy, c)
+			}
+		}
+		if x == b.X && y == b.Y {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y += sy
+		}
+	}
+}
+
+func fillCircle(img *image.Paletted, center image.Point, r int, c uint8) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				setPixel(img, center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+func setPixel(img *image.Paletted, x, y int, c uint8) {
+	if (image.Point{x, y}).In(img.Rect) {
+		img.SetColorIndex(x, y, c)
+	}
+}
+
+// digitFont has a 3x5 pixel glyph for every character drawText can
+// draw, one row per byte, the top bit of three being the left pixel
+var digitFont = map[rune][5]byte{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'/': {1, 1, 2, 4, 4},
+}
+
+// drawText draws digits centered on a point, each pixel of the font
+// scale pixels wide
+func drawText(img *image.Paletted, center image.Point, text string, scale int, c uint8) {
+	width := (4*len(text) - 1) * scale
+	left, top := center.X-width/2, center.Y-5*scale/2
+	for i, ch := range text {
+		glyph := digitFont[ch]
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				for px := 0; px < scale; px++ {
+					for py := 0; py < scale; py++ {
+						setPixel(img, left+(4*i+col)*scale+px, top+row*scale+py, c)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/gif"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func simulateValidFarm(t *testing.T) (*AntFarm, *Simulation) {
+	t.Helper()
+	af := NewAntFarm()
+	if _, err := af.ParseInput("testfarms/validfarm.txt"); err != nil {
+		t.Fatalf("ParseInput() unexpected error: %v", err)
+	}
+	solver, _ := LookupSolver("edmonds-karp")
+	schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	sim, err := af.Simulate(schedule)
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+	return af, sim
+}
+
+func TestDrawFrame(t *testing.T) {
+	af, sim := simulateValidFarm(t)
+	sim.Seek(1)
+	img := af.DrawFrame(sim, FrameOptions{Width: 200, Height: 200})
+	if img.Bounds() != image.Rect(0, 0, 200, 200) {
+		t.Fatalf("DrawFrame() size = %v, want 200x200", img.Bounds())
+	}
+
+	// The farm is a diagonal from (0,0) to (3,3), fitted between the
+	// 30 pixel margins: start in the top left, end in the bottom right
+	// and ant 1 in room1, a third of the way along
+	tests := []struct {
+		x, y int
+		want uint8
+		what string
+	}{
+		{30, 30, frameStart, "start room"},
+		{170, 170, frameEnd, "end room"},
+		{100, 100, framePaths, "path between room1 and room2"},
+		{0, 199, frameBackground, "background"},
+	}
+	for _, tt := range tests {
+		if got := img.ColorIndexAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("DrawFrame() %s at (%d,%d) = color %d, want %d", tt.what, tt.x, tt.y, got, tt.want)
+		}
+	}
+	// Ant 1 is a dot in the first path color around room1, with its
+	// number drawn on it in the background color
+	dot, digits := 0, 0
+	for y := 67 - 8; y <= 67+8; y++ {
+		for x := 67 - 8; x <= 67+8; x++ {
+			switch img.ColorIndexAt(x, y) {
+			case framePaths:
+				dot++
+			case frameBackground:
+				digits++
+			}
+		}
+	}
+	if dot == 0 || digits == 0 {
+		t.Errorf("DrawFrame() has no numbered ant in room1: %d dot and %d digit pixels", dot, digits)
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	af, sim := simulateValidFarm(t)
+	var buf bytes.Buffer
+	if err := af.WriteGIF(&buf, sim, FrameOptions{Width: 120, Height: 90, Delay: 20}); err != nil {
+		t.Fatalf("WriteGIF() unexpected error: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("WriteGIF() wrote an invalid GIF: %v", err)
+	}
+	if len(anim.Image) != sim.Turns()+1 {
+		t.Errorf("WriteGIF() wrote %d frames, want %d", len(anim.Image), sim.Turns()+1)
+	}
+	if anim.Delay[0] != 20 || anim.Delay[len(anim.Delay)-1] != 60 {
+		t.Errorf("WriteGIF() delays = %v, want 20 and 60 for the last frame", anim.Delay)
+	}
+	if sim.Turn() != 0 {
+		t.Errorf("WriteGIF() left the simulation at turn %d", sim.Turn())
+	}
+}
+
+func TestWritePNGs(t *testing.T) {
+	af, sim := simulateValidFarm(t)
+	files, err := af.WritePNGs(t.TempDir(), sim, FrameOptions{Width: 100, Height: 100})
+	if err != nil {
+		t.Fatalf("WritePNGs() unexpected error: %v", err)
+	}
+	if len(files) != sim.Turns()+1 {
+		t.Fatalf("WritePNGs() wrote %d files, want %d", len(files), sim.Turns()+1)
+	}
+	file, err := os.Open(files[len(files)-1])
+	if err != nil {
+		t.Fatalf("cannot open last frame: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil || img.Bounds().Dx() != 100 {
+		t.Errorf("WritePNGs() last frame is not a 100 pixel wide PNG: %v", err)
+	}
+}
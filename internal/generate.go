@@ -0,0 +1,335 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Topologies Generate can build
+var Topologies = []string{"grid", "tree", "layered", "geometric", "bottleneck"}
+
+// GenerateOptions controls the farm Generate builds
+type GenerateOptions struct {
+	Rooms    int     // Number of rooms, including start and end
+	Density  float64 // Extra random links per room, on top of the topology's own
+	Ants     int     // Number of ants
+	Topology string  // One of Topologies
+	Seed     int64   // Same seed and options give the same farm
+}
+
+// Generate builds a random farm and returns it in the format ParseInput
+// reads. Every topology connects the start room to the end room, so the
+// farm is always valid
+func Generate(opts GenerateOptions) (string, error) {
+	if opts.Rooms < 2 {
+		return "", fmt.Errorf("a farm needs at least 2 rooms, got %d", opts.Rooms)
+	}
+	if opts.Ants < 1 {
+		return "", fmt.Errorf("a farm needs at least 1 ant, got %d", opts.Ants)
+	}
+	if opts.Density < 0 {
+		return "", fmt.Errorf("link density cannot be negative, got %v", opts.Density)
+	}
+
+	b := &farmBuilder{
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		links: make(map[[2]int]bool),
+	}
+	switch opts.Topology {
+	case "grid":
+		b.grid(opts.Rooms)
+	case "tree":
+		b.tree(opts.Rooms)
+	case "layered":
+		b.layered(opts.Rooms)
+	case "geometric":
+		b.geometric(opts.Rooms, opts.Density)
+		return b.String(opts.Ants), nil
+	case "bottleneck":
+		b.bottleneck(opts.Rooms)
+	default:
+		return "", fmt.Errorf("unknown topology %q, want one of %s", opts.Topology, strings.Join(Topologies, ", "))
+	}
+	b.addRandomLinks(int(opts.Density * float64(opts.Rooms)))
+	return b.String(opts.Ants), nil
+}
+
+// farmBuilder collects rooms and links by index before naming them
+type farmBuilder struct {
+	rng        *rand.Rand
+	x, y       []int
+	links      map[[2]int]bool
+	linkOrder  [][2]int
+	start, end int
+}
+
+func (b *farmBuilder) addRoom(x, y int) int {
+	b.x = append(b.x, x)
+	b.y = append(b.y, y)
+	return len(b.x) - 1
+}
+
+// link connects two rooms unless they are the same or already linked
+func (b *farmBuilder) link(i, j int) bool {
+	key := [2]int{min(i, j), max(i, j)}
+	if i == j || b.links[key] {
+		return false
+	}
+	b.links[key] = true
+	b.linkOrder = append(b.linkOrder, [2]int{i, j})
+	return true
+}
+
+// addRandomLinks adds n links between random rooms
+func (b *farmBuilder) addRandomLinks(n int) {
+	rooms := len(b.x)
+	maxLinks := rooms * (rooms - 1) / 2
+	for added := 0; added < n && len(b.links) < maxLinks; {
+		if b.link(b.rng.Intn(rooms), b.rng.Intn(rooms)) {
+			added++
+		}
+	}
+}
+
+// grid lays the rooms out in a square with links between neighbours,
+// start and end in opposite corners
+func (b *farmBuilder) grid(rooms int) {
+	width := int(math.Ceil(math.Sqrt(float64(rooms))))
+	for i := 0; i < rooms; i++ {
+		b.addRoom(i%width, i/width)
+		if i%width > 0 {
+			b.link(i-1, i)
+		}
+		if i >= width {
+			b.link(i-width, i)
+		}
+	}
+	b.start, b.end = 0, rooms-1
+}
+
+// tree grows a random tree from the start room, with the end room as the
+// deepest leaf
+func (b *farmBuilder) tree(rooms int) {
+	depth := make([]int, rooms)
+	perLevel := make(map[int]int)
+	b.addRoom(0, 0)
+	perLevel[0] = 1
+	for i := 1; i < rooms; i++ {
+		parent := b.rng.Intn(i)
+		depth[i] = depth[parent] + 1
+		b.addRoom(perLevel[depth[i]], depth[i])
+		perLevel[depth[i]]++
+		b.link(parent, i)
+	}
+	b.start, b.end = 0, 0
+	for i := range depth {
+		if depth[i] > depth[b.end] {
+			b.end = i
+		}
+	}
+}
+
+// layered puts the rooms in columns, each room linked to one or two rooms
+// of the next column, with start before the first and end after the last
+func (b *farmBuilder) layered(rooms int) {
+	inner := rooms - 2
+	layers := max(1, int(math.Round(math.Sqrt(float64(inner)))))
+	b.start = b.addRoom(0, 0)
+	columns := make([][]int, 0, layers)
+	for l := 0; l < layers; l++ {
+		size := inner / layers
+		if l < inner%layers {
+			size++
+		}
+		column := make([]int, 0, size)
+		for k := 0; k < size; k++ {
+			column = append(column, b.addRoom(l+1, k))
+		}
+		if len(column) > 0 {
+			columns = append(columns, column)
+		}
+	}
+	b.end = b.addRoom(len(columns)+1, 0)
+
+	if len(columns) == 0 {
+		b.link(b.start, b.end)
+		return
+	}
+	for _, room := range columns[0] {
+		b.link(b.start, room)
+	}
+	for l := 0; l+1 < len(columns); l++ {
+		next := columns[l+1]
+		for _, room := range columns[l] {
+			b.link(room, next[b.rng.Intn(len(next))])
+			if b.rng.Intn(2) == 0 {
+				b.link(room, next[b.rng.Intn(len(next))])
+			}
+		}
+		// Every room needs a way in
+		for _, room := range next {
+			b.link(columns[l][b.rng.Intn(len(columns[l]))], room)
+		}
+	}
+	for _, room := range columns[len(columns)-1] {
+		b.link(room, b.end)
+	}
+}
+
+// geometric scatters the rooms at random and links each one to its
+// nearest already placed room, then adds the shortest missing links.
+// The end room is the one furthest from the start. Rooms are bucketed in
+// cells of roughly one room each so only nearby rooms are compared
+func (b *farmBuilder) geometric(rooms int, density float64) {
+	const cell = 10
+	size := int(cell * math.Sqrt(float64(rooms)))
+	used := make(map[[2]int]bool)
+	for len(b.x) < rooms {
+		x, y := b.rng.Intn(size+1), b.rng.Intn(size+1)
+		if !used[[2]int{x, y}] {
+			used[[2]int{x, y}] = true
+			b.addRoom(x, y)
+		}
+	}
+	dist := func(i, j int) int {
+		dx, dy := b.x[i]-b.x[j], b.y[i]-b.y[j]
+		return dx*dx + dy*dy
+	}
+	cells := make(map[[2]int][]int)
+	cellOf := func(i int) [2]int { return [2]int{b.x[i] / cell, b.y[i] / cell} }
+	// near returns the rooms placed so far in cells at most r away from c
+	near := func(c [2]int, r int) []int {
+		var found []int
+		for dx := -r; dx <= r; dx++ {
+			for dy := -r; dy <= r; dy++ {
+				found = append(found, cells[[2]int{c[0] + dx, c[1] + dy}]...)
+			}
+		}
+		return found
+	}
+
+	cells[cellOf(0)] = []int{0}
+	for i := 1; i < rooms; i++ {
+		// Widen the search until a room turns up, then once more since a
+		// closer one can sit just outside the first ring that had any
+		var candidates []int
+		for r := 0; len(candidates) == 0; r++ {
+			candidates = near(cellOf(i), r)
+			if len(candidates) > 0 {
+				candidates = near(cellOf(i), r+1)
+			}
+		}
+		nearest := candidates[0]
+		for _, j := range candidates {
+			if dist(i, j) < dist(i, nearest) || (dist(i, j) == dist(i, nearest) && j < nearest) {
+				nearest = j
+			}
+		}
+		b.link(nearest, i)
+		cells[cellOf(i)] = append(cells[cellOf(i)], i)
+	}
+
+	type pair struct{ i, j, d int }
+	var pairs []pair
+	for i := 0; i < rooms; i++ {
+		for _, j := range near(cellOf(i), 1) {
+			if i < j && !b.links[[2]int{i, j}] {
+				pairs = append(pairs, pair{i, j, dist(i, j)})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, c int) bool {
+		if pairs[a].d != pairs[c].d {
+			return pairs[a].d < pairs[c].d
+		}
+		return pairs[a].i < pairs[c].i || (pairs[a].i == pairs[c].i && pairs[a].j < pairs[c].j)
+	})
+	for k := 0; k < int(density*float64(rooms)) && k < len(pairs); k++ {
+		b.link(pairs[k].i, pairs[k].j)
+	}
+
+	b.start, b.end = 0, 0
+	for i := 1; i < rooms; i++ {
+		if dist(0, i) > dist(0, b.end) {
+			b.end = i
+		}
+	}
+}
+
+// bottleneck runs several parallel chains from the start room into a
+// single room, then several more chains from there to the end room, so
+// many paths look available but only one ant gets through per turn
+func (b *farmBuilder) bottleneck(rooms int) {
+	b.start = b.addRoom(0, 0)
+	inner := rooms - 3
+	if inner < 0 {
+		b.end = b.addRoom(1, 0)
+		b.link(b.start, b.end)
+		return
+	}
+	chains := max(1, int(math.Sqrt(float64(inner))/2))
+	chainLen := inner / (2 * chains)
+
+	// Rooms in the second half sit to the right of the bottleneck
+	buildSide := func(from, offset, count int) []int {
+		ends := make([]int, 0, chains)
+		for c := 0; c < chains; c++ {
+			length := count / chains
+			if c < count%chains {
+				length++
+			}
+			prev := from
+			for k := 0; k < length; k++ {
+				room := b.addRoom(offset+k+1, c)
+				b.link(prev, room)
+				prev = room
+			}
+			ends = append(ends, prev)
+		}
+		return ends
+	}
+
+	left := buildSide(b.start, 0, inner/2)
+	middle := b.addRoom(chainLen+2, chains/2)
+	for _, room := range left {
+		b.link(room, middle)
+	}
+	right := buildSide(middle, chainLen+2, inner-inner/2)
+	b.end = b.addRoom(2*chainLen+4, 0)
+	for _, room := range right {
+		b.link(room, b.end)
+	}
+}
+
+// String writes the farm in the input format, rooms in the order they
+// were built with ##start and ##end marking the start and end rooms
+func (b *farmBuilder) String(ants int) string {
+	name := func(i int) string {
+		switch i {
+		case b.start:
+			return "start"
+		case b.end:
+			return "end"
+		}
+		return fmt.Sprintf("r%d", i)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d\n", ants)
+	for i := range b.x {
+		switch i {
+		case b.start:
+			sb.WriteString("##start\n")
+		case b.end:
+			sb.WriteString("##end\n")
+		}
+		fmt.Fprintf(&sb, "%s %d %d\n", name(i), b.x[i], b.y[i])
+	}
+	for _, l := range b.linkOrder {
+		fmt.Fprintf(&sb, "%s-%s\n", name(l[0]), name(l[1]))
+	}
+	return sb.String()
+}
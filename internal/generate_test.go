@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGenerate checks every topology gives a farm that parses and solves
+func TestGenerate(t *testing.T) {
+	for _, topology := range Topologies {
+		for _, rooms := range []int{2, 3, 10, 60} {
+			for seed := int64(1); seed <= 3; seed++ {
+				opts := GenerateOptions{Rooms: rooms, Density: 0.3, Ants: 7, Topology: topology, Seed: seed}
+				input, err := Generate(opts)
+				if err != nil {
+					t.Fatalf("Generate(%+v) unexpected error: %v", opts, err)
+				}
+
+				af := NewAntFarm()
+				if _, err := af.ParseReader(strings.NewReader(input)); err != nil {
+					t.Fatalf("Generate(%+v) gives a farm that doesn't parse: %v\n%s", opts, err, input)
+				}
+				if got := len(af.Rooms()); got != rooms {
+					t.Errorf("Generate(%+v) has %d rooms, want %d", opts, got, rooms)
+				}
+				solver, _ := LookupSolver("mincost")
+				schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+				if err != nil {
+					t.Fatalf("Generate(%+v) gives a farm that can't be solved: %v", opts, err)
+				}
+				if err := af.VerifyMoves(schedule.Lines()); err != nil {
+					t.Errorf("Generate(%+v) gives an invalid schedule: %v", opts, err)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	opts := GenerateOptions{Rooms: 40, Density: 1, Ants: 5, Topology: "geometric", Seed: 42}
+	first, _ := Generate(opts)
+	second, _ := Generate(opts)
+	if first != second {
+		t.Error("Generate() gives different farms for the same seed")
+	}
+	opts.Seed = 43
+	if other, _ := Generate(opts); other == first {
+		t.Error("Generate() gives the same farm for different seeds")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts GenerateOptions
+	}{
+		{"Too few rooms", GenerateOptions{Rooms: 1, Ants: 1, Topology: "grid"}},
+		{"No ants", GenerateOptions{Rooms: 4, Ants: 0, Topology: "grid"}},
+		{"Negative density", GenerateOptions{Rooms: 4, Ants: 1, Density: -1, Topology: "grid"}},
+		{"Unknown topology", GenerateOptions{Rooms: 4, Ants: 1, Topology: "ring"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.opts); err == nil {
+				t.Errorf("Generate(%+v) expected an error", tt.opts)
+			}
+		})
+	}
+}
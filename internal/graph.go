@@ -0,0 +1,400 @@
+package internal
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// graph is the farm with every room numbered, so path search can work on
+// slices instead of maps keyed by room name. It is built once, the first
+// time a solver needs it. Rooms are numbered in name order, so going
+// through neighbours by number breaks ties the same way as going by name
+type graph struct {
+	names      []string // Room names by number
+	links      [][]int  // Rooms each room connects to, in the order they were linked
+	lengths    [][]int  // Turns taken by each tunnel in links, nil when every one takes 1
+	start, end int      // -1 when the farm has no such room
+}
+
+// graph returns the numbered form of the farm, building it if the rooms
+// or links changed since it was last built
+func (af *AntFarm) graph() *graph {
+	af.graphMu.Lock()
+	defer af.graphMu.Unlock()
+	if af.numbered == nil {
+		af.numbered = newGraph(af)
+	}
+	return af.numbered
+}
+
+func newGraph(af *AntFarm) *graph {
+	g := &graph{names: make([]string, 0, len(af.rooms)), start: -1, end: -1}
+	for name := range af.rooms {
+		g.names = append(g.names, name)
+	}
+	sort.Strings(g.names)
+
+	index := make(map[string]int, len(g.names))
+	for i, name := range g.names {
+		index[name] = i
+	}
+	g.links = make([][]int, len(g.names))
+	for i, name := range g.names {
+		conns := af.rooms[name].connections
+		g.links[i] = make([]int, 0, len(conns))
+		for _, conn := range conns {
+			if j, ok := index[conn.name]; ok {
+				g.links[i] = append(g.links[i], j)
+			}
+		}
+	}
+	if len(af.lengths) > 0 {
+		g.lengths = make([][]int, len(g.names))
+		for i, links := range g.links {
+			g.lengths[i] = make([]int, len(links))
+			for k, j := range links {
+				g.lengths[i][k] = af.LinkLength(g.names[i], g.names[j])
+			}
+		}
+	}
+	if af.startRoom != nil {
+		if i, ok := index[af.startRoom.name]; ok {
+			g.start = i
+		}
+	}
+	if af.endRoom != nil {
+		if i, ok := index[af.endRoom.name]; ok {
+			g.end = i
+		}
+	}
+	return g
+}
+
+// terminal reports whether room i is the start or end room, which are
+// never split since they can hold any number of ants
+func (g *graph) terminal(i int) bool {
+	return i == g.start || i == g.end
+}
+
+// Every room other than the start and end is split into an in node, 2i,
+// and an out node, 2i+1, so no room can be used by more than one path.
+// Node numbers keep the order of the old "name in" and "name out" nodes
+func (g *graph) in(i int) int { return 2 * i }
+func (g *graph) out(i int) int {
+	if g.terminal(i) {
+		return 2 * i
+	}
+	return 2*i + 1
+}
+
+// length returns the turns taken by the tunnel from room i to
+// g.links[i][k]
+func (g *graph) length(i, k int) int {
+	if g.lengths == nil {
+		return 1
+	}
+	return g.lengths[i][k]
+}
+
+// distances returns the fewest turns it takes to get from room from to
+// every room, -1 for rooms it isn't connected to
+func (g *graph) distances(from int) []int {
+	dist := make([]int, len(g.names))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[from] = 0
+	if g.lengths == nil {
+		queue := []int{from}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range g.links[current] {
+				if dist[next] < 0 {
+					dist[next] = dist[current] + 1
+					queue = append(queue, next)
+				}
+			}
+		}
+		return dist
+	}
+
+	// Tunnels take different times, so go by Dijkstra instead
+	done := make([]bool, len(g.names))
+	queue := nodeQueue{{node: from, dist: 0}}
+	for len(queue) > 0 {
+		current := queue.pop()
+		if done[current.node] {
+			continue
+		}
+		done[current.node] = true
+		for k, next := range g.links[current.node] {
+			d := current.dist + g.length(current.node, k)
+			if !done[next] && (dist[next] < 0 || d < dist[next]) {
+				dist[next] = d
+				queue.push(nodeDist{node: next, dist: d})
+			}
+		}
+	}
+	return dist
+}
+
+// residualGraph is the split-node graph EdmondsKarp and MinCostFlow push
+// flow through. The edges leaving each node are stored side by side,
+// sorted by the node they lead to, so ties break by room name
+type residualGraph struct {
+	g      *graph
+	first  []int          // First edge leaving each node
+	last   []int          // One past the last edge leaving each node
+	edge   []residualEdge // Every edge, by number
+	ids    []int          // Every edge number in order, for edges to return slices of
+	tunnel [][]int        // Edge for each entry of g.links, -1 when it can't carry flow
+
+	// Scratch space reused by every search
+	parent  []int // Edge each node was reached by
+	seen    []int // Search each node was last reached in
+	search  int
+	level   []int // Edges from the start to each node in the last bfs, -1 if not reached
+	dead    []int // Phase in which followPath found no way on from each node
+	phase   int   // Counts calls to bfs and dijkstra
+	queue   []int
+	heap    nodeQueue
+	settled []int
+	buf     []int
+}
+
+// residualEdge is one way along a tunnel or through a room. Searches
+// spend most of their time reading edges, so they are kept small
+type residualEdge struct {
+	to   int32 // Node it leads to
+	rev  int32 // Reverse edge
+	cap  int8  // Capacity left
+	cost int16 // Turns taken, negative for reverse edges
+}
+
+func newResidualGraph(g *graph) *residualGraph {
+	nodes := 2 * len(g.names)
+	r := &residualGraph{g: g, tunnel: make([][]int, len(g.names))}
+
+	// Edges are first added in pairs, the reverse of edge e being e^1,
+	// then moved next to the other edges leaving the same node
+	var to, cost []int
+	add := func(u, v, c int) int {
+		to = append(to, v, u)
+		cost = append(cost, c, -c)
+		return len(to) - 2
+	}
+
+	// Moving through a room costs nothing, a tunnel the turns it takes
+	for i := range g.names {
+		if !g.terminal(i) {
+			add(g.in(i), g.out(i), 0)
+		}
+	}
+	for i, links := range g.links {
+		r.tunnel[i] = make([]int, len(links))
+		for k, j := range links {
+			r.tunnel[i][k] = -1
+			// Tunnels out of the end room or back into the start room can
+			// never carry flow
+			if i != g.end && j != g.start {
+				r.tunnel[i][k] = add(g.out(i), g.in(j), g.length(i, k))
+			}
+		}
+	}
+
+	// Edge e leaves the node its reverse leads to
+	start := make([]int, nodes+1)
+	for e := range to {
+		start[to[e^1]+1]++
+	}
+	for u := 0; u < nodes; u++ {
+		start[u+1] += start[u]
+	}
+	grouped := make([]int, len(to))
+	next := append([]int(nil), start[:nodes]...)
+	for e := range to {
+		u := to[e^1]
+		grouped[next[u]] = e
+		next[u]++
+	}
+	for u := 0; u < nodes; u++ {
+		edges := grouped[start[u]:start[u+1]]
+		sort.Slice(edges, func(a, b int) bool { return to[edges[a]] < to[edges[b]] })
+	}
+
+	// Searches go outwards from the start room, so storing each node's
+	// edges in that order means they mostly read memory front to back
+	r.first, r.last = make([]int, nodes), make([]int, nodes)
+	placed := make([]bool, nodes)
+	pos := make([]int, len(to))
+	n := 0
+	place := func(u int) {
+		placed[u] = true
+		r.first[u] = n
+		for _, e := range grouped[start[u]:start[u+1]] {
+			pos[e] = n
+			n++
+		}
+		r.last[u] = n
+	}
+	queue := []int{g.out(g.start)}
+	place(queue[0])
+	for head := 0; head < len(queue); head++ {
+		for _, e := range grouped[start[queue[head]]:start[queue[head]+1]] {
+			if v := to[e]; !placed[v] {
+				place(v)
+				queue = append(queue, v)
+			}
+		}
+	}
+	for u := 0; u < nodes; u++ {
+		if !placed[u] {
+			place(u)
+		}
+	}
+
+	r.edge, r.ids = make([]residualEdge, len(to)), make([]int, len(to))
+	for e := range to {
+		r.ids[e] = e
+		r.edge[pos[e]] = residualEdge{
+			to:   int32(to[e]),
+			rev:  int32(pos[e^1]),
+			cap:  int8(1 - e%2),
+			cost: int16(cost[e]),
+		}
+	}
+	for i := range r.tunnel {
+		for k, e := range r.tunnel[i] {
+			if e >= 0 {
+				r.tunnel[i][k] = pos[e]
+			}
+		}
+	}
+
+	r.parent = make([]int, nodes)
+	r.seen = make([]int, nodes)
+	r.dead = make([]int, nodes)
+	r.level = make([]int, nodes)
+	for u := range r.level {
+		r.level[u] = -1
+	}
+	return r
+}
+
+// edges returns the edges leaving node u in order of the node they lead
+// to. When rng is set the order is shuffled instead to break ties between
+// equal paths randomly
+func (r *residualGraph) edges(u int, rng *rand.Rand) []int {
+	edges := r.ids[r.first[u]:r.last[u]]
+	if rng == nil {
+		return edges
+	}
+	r.buf = append(r.buf[:0], edges...)
+	rng.Shuffle(len(r.buf), func(i, j int) {
+		r.buf[i], r.buf[j] = r.buf[j], r.buf[i]
+	})
+	return r.buf
+}
+
+// augment sends one ant along a path of edges
+func (r *residualGraph) augment(path []int) {
+	for _, e := range path {
+		r.edge[e].cap--             // Decrease forward edge
+		r.edge[r.edge[e].rev].cap++ // Increase reverse edge
+	}
+}
+
+// pathTo follows parent edges back from node v to the start room and
+// returns the edges in order
+func (r *residualGraph) pathTo(v int) []int {
+	path := make([]int, 0)
+	for v != r.g.out(r.g.start) {
+		e := r.parent[v]
+		path = append(path, e)
+		v = int(r.edge[r.edge[e].rev].to)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// followPath finds a path from start to end over edges with capacity
+// left that usable allows, or returns nil. Nodes it has given up on since
+// the last bfs or dijkstra are skipped, so repeated calls stay cheap; if
+// that misses a path, the next full search finds it
+func (r *residualGraph) followPath(rng *rand.Rand, usable func(u, e, v int) bool) []int {
+	start, end := r.g.out(r.g.start), r.g.in(r.g.end)
+	r.search++
+	r.seen[start] = r.search
+
+	// Depth-first, so the search heads for the end room straight away
+	type frame struct {
+		node  int
+		edges []int
+	}
+	edges := func(u int) []int {
+		if rng == nil {
+			return r.edges(u, nil)
+		}
+		return append([]int(nil), r.edges(u, rng)...)
+	}
+	stack := []frame{{start, edges(start)}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if len(top.edges) == 0 {
+			r.dead[top.node] = r.phase
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		e := top.edges[0]
+		top.edges = top.edges[1:]
+		next := int(r.edge[e].to)
+		if r.edge[e].cap <= 0 || r.seen[next] == r.search || r.dead[next] == r.phase ||
+			!usable(top.node, e, next) {
+			continue
+		}
+		r.seen[next] = r.search
+		r.parent[next] = e
+		if next == end {
+			return r.pathTo(end)
+		}
+		stack = append(stack, frame{next, edges(next)})
+	}
+	return nil
+}
+
+// paths walks the flow leaving the start room and returns one path of
+// room names per unit of flow reaching the end room
+func (r *residualGraph) paths() [][]string {
+	g := r.g
+	paths := make([][]string, 0)
+	// A tunnel carries flow when its reverse edge has capacity
+	next := func(i int) int {
+		for k, j := range g.links[i] {
+			if e := r.tunnel[i][k]; e >= 0 && r.edge[r.edge[e].rev].cap > 0 {
+				return j
+			}
+		}
+		return -1
+	}
+
+	for k, first := range g.links[g.start] {
+		if e := r.tunnel[g.start][k]; e < 0 || r.edge[r.edge[e].rev].cap == 0 {
+			continue
+		}
+		path := []string{g.names[g.start], g.names[first]}
+		current := first
+		for current != g.end {
+			if current = next(current); current < 0 {
+				break
+			}
+			path = append(path, g.names[current])
+		}
+		if current == g.end {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
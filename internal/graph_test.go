@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewGraph(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "b"}, {"b", "a"}, {"a", "end"}, {"start", "a"}})
+	g := af.graph()
+
+	if want := []string{"a", "b", "end", "start"}; !reflect.DeepEqual(g.names, want) {
+		t.Errorf("graph names = %v, want %v", g.names, want)
+	}
+	if g.start != 3 || g.end != 2 {
+		t.Errorf("graph start, end = %d, %d, want 3, 2", g.start, g.end)
+	}
+	// Links keep the order they were made in
+	if want := [][]int{{1, 2, 3}, {3, 0}, {0}, {1, 0}}; !reflect.DeepEqual(g.links, want) {
+		t.Errorf("graph links = %v, want %v", g.links, want)
+	}
+	if got, want := g.distances(g.start), []int{1, 1, 2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("distances() = %v, want %v", got, want)
+	}
+
+	if af.graph() != g {
+		t.Error("graph() rebuilt an unchanged farm")
+	}
+	af.ParseRoom("c 0 0", false, false)
+	if af.graph() == g {
+		t.Error("graph() didn't rebuild after a room was added")
+	}
+}
+
+// largeFarm returns a generated farm with 100,000 rooms
+func largeFarm(topology string) string {
+	input, err := Generate(GenerateOptions{Rooms: 100_000, Density: 0.5, Ants: 500, Topology: topology, Seed: 1})
+	if err != nil {
+		panic(err)
+	}
+	return input
+}
+
+// TestLargeFarm checks a farm far bigger than the examples still parses
+// and solves correctly; the benchmarks show how long it takes
+func TestLargeFarm(t *testing.T) {
+	if testing.Short() {
+		t.Skip("large farm skipped in short mode")
+	}
+	af := NewAntFarm()
+	if _, err := af.ParseReader(strings.NewReader(largeFarm("layered"))); err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	for _, name := range []string{"edmonds-karp", "mincost"} {
+		solver, _ := LookupSolver(name)
+		schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+		if err != nil {
+			t.Fatalf("%s: Solve() unexpected error: %v", name, err)
+		}
+		if err := af.VerifyMoves(schedule.Lines()); err != nil {
+			t.Errorf("%s: Solve() gives an invalid schedule: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GridOptions controls how DrawGrid lays out the farm
+type GridOptions struct {
+	Width, Height int  // Size of the grid in characters
+	Follow        int  // Ant whose route is highlighted, 0 for none
+	Color         bool // Color paths and ants with ANSI escape codes
+}
+
+// Characters DrawGrid draws with
+const (
+	gridTunnel   = '.' // Tunnel no path uses
+	gridPath     = '+' // Tunnel on a path
+	gridRoute    = '#' // Tunnel on the followed ant's route
+	gridRoom     = 'o'
+	gridStart    = 'S'
+	gridEnd      = 'E'
+	gridOccupied = '@' // Room holding an ant
+	gridFollowed = '*' // Room holding the followed ant
+)
+
+// gridCell is one character of the grid and how to highlight it
+type gridCell struct {
+	ch      byte
+	color   string // Path color, "" for none
+	reverse bool
+}
+
+// DrawGrid draws the farm on a grid of characters, room coordinates
+// scaled to fit, as it is at the simulation's current turn. Tunnels on a
+// path and rooms holding an ant take the color of the path, and the
+// followed ant's whole route stands out
+func (af *AntFarm) DrawGrid(sim *Simulation, opts GridOptions) []string {
+	width, height := max(opts.Width, 1), max(opts.Height, 1)
+	grid := make([][]gridCell, height)
+	for row := range grid {
+		grid[row] = make([]gridCell, width)
+		for col := range grid[row] {
+			grid[row][col].ch = ' '
+		}
+	}
+
+	rooms := af.Rooms()
+	if len(rooms) == 0 {
+		return renderGrid(grid, opts.Color)
+	}
+	minX, maxX, minY, maxY := rooms[0].x, rooms[0].x, rooms[0].y, rooms[0].y
+	for _, room := range rooms {
+		minX, maxX = min(minX, room.x), max(maxX, room.x)
+		minY, maxY = min(minY, room.y), max(maxY, room.y)
+	}
+	scale := func(v, lo, hi, size int) int {
+		if hi == lo {
+			return (size - 1) / 2
+		}
+		return (v - lo) * (size - 1) / (hi - lo)
+	}
+	cells := make(map[string][2]int, len(rooms))
+	for _, room := range rooms {
+		cells[room.name] = [2]int{scale(room.x, minX, maxX, width), scale(room.y, minY, maxY, height)}
+	}
+
+	pathColor := make(map[[2]string]string)
+	for i, path := range sim.Paths() {
+		for k := 1; k < len(path); k++ {
+			if key := linkKey(path[k-1], path[k]); pathColor[key] == "" {
+				pathColor[key] = PathColor(i)
+			}
+		}
+	}
+	route := make(map[[2]string]bool)
+	followed, ok := sim.Ant(opts.Follow)
+	if ok {
+		for k := 1; k < len(followed.Route); k++ {
+			route[linkKey(followed.Route[k-1], followed.Route[k])] = true
+		}
+	}
+
+	for _, link := range af.links {
+		key := linkKey(link[0], link[1])
+		cell := gridCell{ch: gridTunnel}
+		switch {
+		case route[key]:
+			cell = gridCell{ch: gridRoute, color: pathColor[key]}
+		case pathColor[key] != "":
+			cell = gridCell{ch: gridPath, color: pathColor[key]}
+		}
+		drawLine(grid, cells[link[0]], cells[link[1]], cell)
+	}
+
+	// Rooms can share a cell when the grid is small, so plain rooms are
+	// drawn first and never hide the others
+	occupied := sim.Occupied()
+	highlighted := make([]gridCell, len(rooms))
+	for i, room := range rooms {
+		cell := gridCell{ch: gridRoom}
+		switch {
+		case room == af.startRoom:
+			cell.ch = gridStart
+		case room == af.endRoom:
+			cell.ch = gridEnd
+		case occupied[room.name] != 0:
+			cell.ch = gridOccupied
+			if ant, _ := sim.Ant(occupied[room.name]); ant.Path >= 0 {
+				cell.color = PathColor(ant.Path)
+			}
+		}
+		if followed.Ant != 0 && room.name == followed.Room {
+			cell.reverse = true
+			if cell.ch == gridOccupied {
+				cell.ch = gridFollowed
+			}
+		}
+		highlighted[i] = cell
+	}
+	for _, plain := range []bool{true, false} {
+		for i, room := range rooms {
+			if cell := highlighted[i]; (cell == gridCell{ch: gridRoom}) == plain {
+				c := cells[room.name]
+				grid[c[1]][c[0]] = cell
+			}
+		}
+	}
+	return renderGrid(grid, opts.Color)
+}
+
+// drawLine draws cell on every grid cell between two points, leaving out
+// the points themselves, with Bresenham's algorithm
+func drawLine(grid [][]gridCell, from, to [2]int, cell gridCell) {
+	x, y := from[0], from[1]
+	dx, dy := abs(to[0]-x), -abs(to[1]-y)
+	sx, sy := sign(to[0]-x), sign(to[1]-y)
+	e := dx + dy
+	for {
+		if [2]int{x, y} == to {
+			return
+		}
+		if [2]int{x, y} != from {
+			grid[y][x] = cell
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// renderGrid turns the grid into lines, with ANSI escape codes for the
+// highlights when color is set
+func renderGrid(grid [][]gridCell, color bool) []string {
+	lines := make([]string, len(grid))
+	for row, cells := range grid {
+		var sb strings.Builder
+		for _, cell := range cells {
+			if !color || (cell.color == "" && !cell.reverse) {
+				sb.WriteByte(cell.ch)
+				continue
+			}
+			if cell.color != "" {
+				fmt.Fprintf(&sb, "\033[1;38;5;%dm", ansi256(cell.color))
+			}
+			if cell.reverse {
+				sb.WriteString("\033[7m")
+			}
+			sb.WriteByte(cell.ch)
+			sb.WriteString("\033[0m")
+		}
+		lines[row] = strings.TrimRight(sb.String(), " ")
+	}
+	return lines
+}
+
+// ansi256 returns the closest of the 216 color cube entries of a 256
+// color terminal to a #rrggbb color
+func ansi256(hex string) int {
+	level := func(i int) int {
+		v, _ := strconv.ParseUint(hex[i:i+2], 16, 8)
+		return int(v*5+127) / 255
+	}
+	return 16 + 36*level(1) + 6*level(3) + level(5)
+}
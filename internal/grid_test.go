@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDrawGrid(t *testing.T) {
+	// start and end on the top corners, a below start and b below end
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "b"}, {"b", "end"}, {"start", "end"}})
+	af.rooms["end"].x = 10
+	af.rooms["b"].x, af.rooms["b"].y = 10, 4
+	af.rooms["a"].y = 4
+	af.order = []string{"start", "a", "b", "end"}
+	af.links = [][2]string{{"start", "a"}, {"a", "b"}, {"b", "end"}, {"start", "end"}}
+	af.numAnts = 2
+	sim, err := af.Simulate(&Schedule{
+		Paths: [][]string{{"start", "end"}, {"start", "a", "b", "end"}},
+		Turns: [][]Move{{{1, "end"}, {2, "a"}}, {{2, "b"}}, {{2, "end"}}},
+	})
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+
+	sim.Seek(1)
+	got := af.DrawGrid(sim, GridOptions{Width: 11, Height: 3})
+	want := []string{
+		"S+++++++++E",
+		"+         +",
+		"@+++++++++o",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DrawGrid() after turn 1 =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	sim.Seek(2)
+	got = af.DrawGrid(sim, GridOptions{Width: 11, Height: 3, Follow: 2})
+	want = []string{
+		"S+++++++++E",
+		"#         #",
+		"o#########*",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DrawGrid() following ant 2 =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	colored := af.DrawGrid(sim, GridOptions{Width: 11, Height: 3, Follow: 2, Color: true})
+	if !strings.Contains(colored[2], "\033[7m*\033[0m") || !strings.Contains(colored[0], "\033[1;38;5;") {
+		t.Errorf("DrawGrid() with color has no highlights: %q", colored)
+	}
+}
+
+func TestAnsi256(t *testing.T) {
+	tests := map[string]int{"#000000": 16, "#ffffff": 231, "#ff0000": 196, "#e41a1c": 167}
+	for hex, want := range tests {
+		if got := ansi256(hex); got != want {
+			t.Errorf("ansi256(%s) = %d, want %d", hex, got, want)
+		}
+	}
+}
+
+func TestDrawLine(t *testing.T) {
+	// Every slope has to reach the end point, and fill the cells between
+	for _, to := range [][2]int{{9, 0}, {9, 3}, {9, 9}, {3, 9}, {0, 9}, {0, 0}, {5, 1}, {1, 8}, {2, 1}, {4, 3}, {6, 5}, {5, 7}} {
+		grid := make([][]gridCell, 10)
+		for row := range grid {
+			grid[row] = make([]gridCell, 10)
+		}
+		drawLine(grid, [2]int{0, 0}, to, gridCell{ch: '#'})
+		want := max(to[0], to[1]) - 1
+		count := 0
+		for _, row := range grid {
+			for _, cell := range row {
+				if cell.ch == '#' {
+					count++
+				}
+			}
+		}
+		if count != max(want, 0) {
+			t.Errorf("drawLine() to %v filled %d cells, want %d", to, count, max(want, 0))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package internal
+
+// LowerBound returns a number of turns no valid solution can beat, based
+// on the flow found by the last call to EdmondsKarp. No more ants than the
+// size of the maximum flow can reach the end room in one turn, and none
+// can get there before walking the shortest path, so the best case is that
+// many shortest paths used side by side. 0 is returned when there is no path
+func (af *AntFarm) LowerBound() int {
+	return lowerBound(af.maxFlow, af.shortest, af.numAnts)
+}
+
+func lowerBound(maxFlow, shortest, numAnts int) int {
+	if maxFlow == 0 {
+		return 0
+	}
+	paths := make([]PathInfo, maxFlow)
+	for i := range paths {
+		paths[i] = PathInfo{length: shortest}
+	}
+	turns, _ := findOptimalTurns(paths, numAnts)
+	return turns
+}
+
+// maxFlowBound returns a number of paths the maximum flow can't go over,
+// for when the search didn't get to the end: every path needs a tunnel of
+// its own out of the start room and into the end room
+func (af *AntFarm) maxFlowBound() int {
+	g := af.graph()
+	if g.start < 0 || g.end < 0 {
+		return 0
+	}
+	return min(len(g.links[g.start]), len(g.links[g.end]))
+}
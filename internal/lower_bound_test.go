@@ -0,0 +1,42 @@
+package internal
+
+import "testing"
+
+func TestAntFarm_LowerBound(t *testing.T) {
+	// Shortest path start-a-b-end has length 3, but a flow of two needs
+	// two paths of length 5
+	links := [][2]string{
+		{"start", "a"}, {"a", "b"}, {"b", "end"},
+		{"a", "d1"}, {"d1", "d2"}, {"d2", "d3"}, {"d3", "end"},
+		{"start", "c"}, {"c", "c1"}, {"c1", "c2"}, {"c2", "b"},
+	}
+	tests := []struct {
+		name      string
+		numAnts   int
+		wantBound int
+		wantTurns int
+	}{
+		{name: "One ant", numAnts: 1, wantBound: 3, wantTurns: 3},
+		{name: "Ten ants", numAnts: 10, wantBound: 7, wantTurns: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := buildFarm("start", "end", links)
+			af.numAnts = tt.numAnts
+			af.EdmondsKarp()
+			moves := af.SimulateAnts()
+
+			if got := af.LowerBound(); got != tt.wantBound {
+				t.Errorf("LowerBound() = %v, want %v", got, tt.wantBound)
+			}
+			if got := len(moves); got != tt.wantTurns {
+				t.Errorf("SimulateAnts() turns = %v, want %v", got, tt.wantTurns)
+			}
+		})
+	}
+
+	if got := buildFarm("start", "end", nil).LowerBound(); got != 0 {
+		t.Errorf("LowerBound() without a path = %v, want 0", got)
+	}
+}
@@ -1,65 +1,93 @@
 package internal
 
-// Edmonds-Karp algroithm to find augmenting paths
-func (af *AntFarm) EdmondsKarp() {
-	// Create residual graph
-	residualGraph := make(map[string]map[string]int)
+import (
+	"context"
+	"math/rand"
+)
 
-	// Inialize residual graph
-	for name, room := range af.rooms {
-		residualGraph[name] = make(map[string]int)
-		for _, conn := range room.connections {
-			residualGraph[name][conn.name] = 1 // Initial capacity of 1 for each edge
-		}
-	}
+func init() {
+	RegisterSolver("edmonds-karp", flowSolver((*AntFarm).edmondsKarp))
+}
 
-	af.paths = make([][]string, 0)
+// Edmonds-Karp algroithm to find room-disjoint paths. The path set kept in
+// af.paths is the one, across every flow level, that moves all the ants in
+// the fewest turns; the chosen flow level (number of paths) is returned
+func (af *AntFarm) EdmondsKarp() int {
+	af.usePathSet(af.edmondsKarp(context.Background(), af.numAnts))
+	return len(af.paths)
+}
+
+// edmondsKarp runs Edmonds-Karp for numAnts ants without changing the farm.
+// Once ctx is done it stops between paths and returns the best set so far
+func (af *AntFarm) edmondsKarp(ctx context.Context, numAnts int) *pathSet {
+	g := af.graph()
+	if g.lengths != nil {
+		// bfs counts tunnels, not turns. The shortest augmenting path by
+		// turns is the one min-cost flow takes, so leave it to that
+		return af.minCostFlow(ctx, numAnts)
+	}
+	best := newPathSet(af, numAnts)
+	if g.start < 0 || g.end < 0 {
+		return best
+	}
+	residual := newResidualGraph(g)
+	rng := af.newRand()
 
-	// Keep finding paths until no more paths exist
-	for {
-		path := af.bfs(residualGraph)
+	// Keep finding paths until no more paths exist. Every path as short as
+	// the one bfs found is taken before searching the whole graph again
+	for !best.stop(ctx) {
+		path := residual.bfs(rng)
 		if len(path) == 0 {
 			break
 		}
-		af.paths = append(af.paths, path)
+		for len(path) > 0 && !best.stop(ctx) {
+			residual.augment(path)
 
-		// Update residual graph
-		for i := 0; i < len(path)-1; i++ {
-			u, v := path[i], path[i+1]
-			residualGraph[u][v]--         // Decrease forward edge
-			if residualGraph[v][u] == 0 { // Add reverse edge if it doesn't exist
-				residualGraph[v][u] = 1
-			}
+			// Augmenting paths may cancel each other out through reverse
+			// edges, so rebuild the paths from the current flow instead
+			best.add(residual.paths())
+			path = residual.levelPath(rng)
 		}
 	}
+
+	return best
 }
 
-// bfs implements breath-first search to find shortest augmenting path
-func (af *AntFarm) bfs(residualGraph map[string]map[string]int) []string {
-	visited := make(map[string]bool)
-	parent := make(map[string]string)
-	queue := []string{af.startRoom.name}
-	visited[af.startRoom.name] = true
+// bfs implements breath-first search to find the shortest augmenting path,
+// returned as a list of edges. It also records how many edges each node
+// it reaches is from the start, for levelPath
+func (r *residualGraph) bfs(rng *rand.Rand) []int {
+	start, end := r.g.out(r.g.start), r.g.in(r.g.end)
+	for _, u := range r.queue {
+		r.level[u] = -1
+	}
+	r.search++
+	r.phase++
+	r.seen[start], r.level[start] = r.search, 0
+	r.queue = append(r.queue[:0], start)
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		for next, capacity := range residualGraph[current] {
-			if !visited[next] && capacity > 0 {
-				visited[next] = true
-				parent[next] = current
-				queue = append(queue, next)
-				if next == af.endRoom.name {
-					// Construct Path
-					path := []string{next}
-					for p := current; p != af.startRoom.name; p = parent[p] {
-						path = append([]string{p}, path...)
-					}
-					path = append([]string{af.startRoom.name}, path...)
-					return path
+	for head := 0; head < len(r.queue); head++ {
+		current := r.queue[head]
+		for _, e := range r.edges(current, rng) {
+			next := int(r.edge[e].to)
+			if r.edge[e].cap > 0 && r.seen[next] != r.search {
+				r.seen[next], r.level[next] = r.search, r.level[current]+1
+				r.parent[next] = e
+				r.queue = append(r.queue, next)
+				if next == end {
+					return r.pathTo(next)
 				}
 			}
 		}
 	}
-	return []string{}
+	return nil
+}
+
+// levelPath finds another augmenting path as short as the one bfs last
+// found, by only moving to nodes one edge further from the start, without
+// a full search
+func (r *residualGraph) levelPath(rng *rand.Rand) []int {
+	return r.followPath(rng, func(u, e, v int) bool {
+		return r.level[v] == r.level[u]+1
+	})
 }
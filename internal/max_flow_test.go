@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -118,30 +120,207 @@ func TestEdmondsKarp(t *testing.T) {
 }
 
 func TestBFS(t *testing.T) {
-	af := &AntFarm{
-		rooms: map[string]*Room{
-			"start": {name: "start"},
-			"1":     {name: "1"},
-			"end":   {name: "end"},
-		},
-		startRoom: &Room{name: "start"},
-		endRoom:   &Room{name: "end"},
+	af := buildFarm("start", "end", [][2]string{{"start", "1"}, {"1", "end"}})
+	g := af.graph()
+	residual := newResidualGraph(g)
+	room := func(name string) int {
+		for i, n := range g.names {
+			if n == name {
+				return i
+			}
+		}
+		return -1
 	}
 
-	residualGraph := map[string]map[string]int{
-		"start": {"1": 1},
-		"1":     {"end": 1},
-		"end":   {},
+	// Into room 1, through it, then out to end
+	want := []int{g.in(room("1")), g.out(room("1")), g.in(room("end"))}
+	path := residual.bfs(nil)
+	got := make([]int, 0, len(path))
+	for _, e := range path {
+		got = append(got, int(residual.edge[e].to))
 	}
-
-	want := []string{"start", "1", "end"}
-	if got := af.bfs(residualGraph); !reflect.DeepEqual(got, want) {
-		t.Errorf("bfs() = %v, want %v", got, want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bfs() visits nodes %v, want %v", got, want)
 	}
 
 	// Test with no available path
-	residualGraph["1"]["end"] = 0
-	if got := af.bfs(residualGraph); len(got) != 0 {
+	residual.augment(path)
+	if got := residual.bfs(nil); len(got) != 0 {
 		t.Errorf("bfs() = %v, want empty path", got)
 	}
 }
+
+// buildFarm creates a farm with undirected links between the given rooms
+func buildFarm(start, end string, links [][2]string) *AntFarm {
+	af := NewAntFarm()
+	room := func(name string) *Room {
+		if r, ok := af.rooms[name]; ok {
+			return r
+		}
+		r := &Room{name: name, connections: []*Room{}}
+		af.rooms[name] = r
+		return r
+	}
+	af.startRoom = room(start)
+	af.endRoom = room(end)
+	for _, link := range links {
+		a, b := room(link[0]), room(link[1])
+		a.connections = append(a.connections, b)
+		b.connections = append(b.connections, a)
+	}
+	return af
+}
+
+func TestEdmondsKarpRoomDisjoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   [][2]string
+		wantLen int
+	}{
+		{
+			// Two edge-disjoint paths exist but both have to cross room c
+			name: "Shared bottleneck room",
+			links: [][2]string{
+				{"start", "a"}, {"start", "b"}, {"a", "c"}, {"b", "c"},
+				{"c", "d"}, {"c", "e"}, {"d", "end"}, {"e", "end"},
+			},
+			wantLen: 1,
+		},
+		{
+			// Whichever path is found first through b has to be rerouted
+			name: "Rerouted through reverse edge",
+			links: [][2]string{
+				{"start", "a"}, {"a", "b"}, {"b", "end"},
+				{"start", "c"}, {"c", "b"}, {"a", "d"}, {"d", "end"},
+			},
+			wantLen: 2,
+		},
+		{
+			name:    "Direct link",
+			links:   [][2]string{{"start", "end"}, {"start", "a"}, {"a", "end"}},
+			wantLen: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := buildFarm("start", "end", tt.links)
+			af.EdmondsKarp()
+
+			if got := len(af.paths); got != tt.wantLen {
+				t.Fatalf("EdmondsKarp() path count = %v, want %v (%v)", got, tt.wantLen, af.paths)
+			}
+
+			used := make(map[string]bool)
+			for _, path := range af.paths {
+				if path[0] != "start" || path[len(path)-1] != "end" {
+					t.Errorf("Path doesn't go from start to end: %v", path)
+				}
+				for i, name := range path {
+					if i > 0 {
+						connected := false
+						for _, conn := range af.rooms[path[i-1]].connections {
+							if conn.name == name {
+								connected = true
+							}
+						}
+						if !connected {
+							t.Errorf("Invalid path: rooms %s and %s are not connected", path[i-1], name)
+						}
+					}
+					if name == "start" || name == "end" {
+						continue
+					}
+					if used[name] {
+						t.Errorf("Room %s is used by more than one path: %v", name, af.paths)
+					}
+					used[name] = true
+				}
+			}
+		})
+	}
+}
+
+func TestEdmondsKarpFlowLevel(t *testing.T) {
+	// The shortest path start-a-b-end has to be rerouted to reach a flow
+	// of two, which makes both resulting paths longer
+	links := [][2]string{
+		{"start", "a"}, {"a", "b"}, {"b", "end"},
+		{"a", "d1"}, {"d1", "d2"}, {"d2", "d3"}, {"d3", "end"},
+		{"start", "c"}, {"c", "c1"}, {"c1", "c2"}, {"c2", "b"},
+	}
+	tests := []struct {
+		name      string
+		numAnts   int
+		wantLevel int
+	}{
+		{name: "Few ants keep the short path", numAnts: 1, wantLevel: 1},
+		{name: "Many ants use the full flow", numAnts: 10, wantLevel: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := buildFarm("start", "end", links)
+			af.numAnts = tt.numAnts
+			if got := af.EdmondsKarp(); got != tt.wantLevel {
+				t.Errorf("EdmondsKarp() flow level = %v, want %v", got, tt.wantLevel)
+			}
+			if got := len(af.paths); got != tt.wantLevel {
+				t.Errorf("EdmondsKarp() path count = %v, want %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestEdmondsKarpDeterministic(t *testing.T) {
+	// Plenty of equally short paths so map ordering would show up
+	links := [][2]string{
+		{"start", "a"}, {"start", "b"}, {"start", "c"},
+		{"a", "x"}, {"a", "y"}, {"b", "x"}, {"b", "z"}, {"c", "y"}, {"c", "z"},
+		{"x", "end"}, {"y", "end"}, {"z", "end"},
+	}
+	solve := func(seed int64) ([][]string, []string) {
+		af := buildFarm("start", "end", links)
+		af.numAnts = 5
+		if seed != 0 {
+			af.SetSeed(seed)
+		}
+		af.EdmondsKarp()
+		return af.paths, af.SimulateAnts()
+	}
+
+	for _, seed := range []int64{0, 42} {
+		wantPaths, wantMoves := solve(seed)
+		for i := 0; i < 20; i++ {
+			paths, moves := solve(seed)
+			if !reflect.DeepEqual(paths, wantPaths) {
+				t.Fatalf("seed %d: EdmondsKarp() paths = %v, want %v", seed, paths, wantPaths)
+			}
+			if !reflect.DeepEqual(moves, wantMoves) {
+				t.Fatalf("seed %d: SimulateAnts() = %v, want %v", seed, moves, wantMoves)
+			}
+		}
+	}
+}
+
+// benchmarkSolver times a solver on large farms of each topology
+func benchmarkSolver(b *testing.B, name string) {
+	solver, _ := LookupSolver(name)
+	for _, topology := range Topologies {
+		af := NewAntFarm()
+		if _, err := af.ParseReader(strings.NewReader(largeFarm(topology))); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(topology, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := solver.Solve(context.Background(), af, af.numAnts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEdmondsKarp(b *testing.B) {
+	benchmarkSolver(b, "edmonds-karp")
+}
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"context"
+	"math/rand"
+)
+
+func init() {
+	RegisterSolver("mincost", flowSolver((*AntFarm).minCostFlow))
+}
+
+// MinCostFlow finds room-disjoint paths like EdmondsKarp, but for every
+// flow level k the k paths have the smallest possible total length. It
+// uses successive shortest paths: each augmenting path is the cheapest one
+// in the residual graph, found with Dijkstra over costs made non-negative
+// by node potentials. Once Dijkstra has found the cheapest cost, every
+// other path at that cost is taken before searching again. As with
+// EdmondsKarp, af.paths is set to the level that moves all the ants in
+// the fewest turns, and that level is returned
+func (af *AntFarm) MinCostFlow() int {
+	af.usePathSet(af.minCostFlow(context.Background(), af.numAnts))
+	return len(af.paths)
+}
+
+// minCostFlow runs min-cost flow for numAnts ants without changing the
+// farm, stopping between paths once ctx is done
+func (af *AntFarm) minCostFlow(ctx context.Context, numAnts int) *pathSet {
+	best := newPathSet(af, numAnts)
+	g := af.graph()
+	if g.start < 0 || g.end < 0 {
+		return best
+	}
+	residual := newResidualGraph(g)
+	rng := af.newRand()
+	potential := make([]int, len(residual.parent))
+	dist := make([]int, len(residual.parent))
+
+	for !best.stop(ctx) {
+		path := residual.dijkstra(potential, dist, rng)
+		if len(path) == 0 {
+			break
+		}
+		for len(path) > 0 && !best.stop(ctx) {
+			residual.augment(path)
+			best.add(residual.paths())
+			path = residual.zeroPath(potential, rng)
+		}
+	}
+
+	return best
+}
+
+// dijkstra finds the cheapest path from start to end in the residual graph
+// using reduced costs cost(u, v) + potential(u) - potential(v), which stay
+// non-negative as long as the potentials are updated after every search.
+// dist is scratch space with one entry per node
+func (r *residualGraph) dijkstra(potential, dist []int, rng *rand.Rand) []int {
+	start, end := r.g.out(r.g.start), r.g.in(r.g.end)
+	// A node is reached when seen is this search, and done when it is the
+	// next one
+	r.search += 2
+	r.phase++
+	reached, done := r.search-1, r.search
+	r.seen[start], dist[start] = reached, 0
+	queue := r.heap[:0]
+	queue.push(nodeDist{node: start, dist: 0})
+	settled := r.settled[:0]
+
+	for len(queue) > 0 {
+		current := queue.pop()
+		if r.seen[current.node] == done {
+			continue
+		}
+		r.seen[current.node] = done
+		settled = append(settled, current.node)
+		if current.node == end {
+			break
+		}
+
+		for _, e := range r.edges(current.node, rng) {
+			next := int(r.edge[e].to)
+			if r.edge[e].cap <= 0 || r.seen[next] == done {
+				continue
+			}
+			reduced := int(r.edge[e].cost) + potential[current.node] - potential[next]
+			d := current.dist + reduced
+			if r.seen[next] != reached || d < dist[next] {
+				r.seen[next], dist[next] = reached, d
+				r.parent[next] = e
+				queue.push(nodeDist{node: next, dist: d})
+			}
+		}
+	}
+
+	r.heap, r.settled = queue, settled
+	if r.seen[end] != done {
+		return nil
+	}
+	// The search stops at the end room, so nodes not settled yet are at
+	// least as far. Counting them as exactly that far keeps every reduced
+	// cost non-negative; subtracting that distance from every potential
+	// changes no reduced cost and leaves the unsettled ones as they are
+	for _, node := range settled {
+		potential[node] += dist[node] - dist[end]
+	}
+	return r.pathTo(end)
+}
+
+// zeroPath finds a path from start to end using only edges with a
+// reduced cost of 0. After dijkstra those are the edges on cheapest paths,
+// so this finds another path as cheap as the last without a full search
+func (r *residualGraph) zeroPath(potential []int, rng *rand.Rand) []int {
+	return r.followPath(rng, func(u, e, v int) bool {
+		return int(r.edge[e].cost)+potential[u]-potential[v] == 0
+	})
+}
+
+// nodeDist is a node waiting in the dijkstra queue
+type nodeDist struct {
+	node int
+	dist int
+}
+
+// nodeQueue is a min-heap of nodes by distance, then number so the order
+// doesn't depend on how the nodes were pushed. It is written out rather
+// than using container/heap, which boxes every item it is given
+type nodeQueue []nodeDist
+
+func (q nodeQueue) less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].node < q[j].node
+}
+
+func (q *nodeQueue) push(item nodeDist) {
+	*q = append(*q, item)
+	h := *q
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			break
+		}
+		h[i], h[parent] = h[parent], h[i]
+		i = parent
+	}
+}
+
+func (q *nodeQueue) pop() nodeDist {
+	h := *q
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	for i := 0; ; {
+		smallest := i
+		if l := 2*i + 1; l < len(h) && h.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < len(h) && h.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+	*q = h
+	return top
+}
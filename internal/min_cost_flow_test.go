@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Edmonds-Karp ends up with paths of total length 13 on this farm, while
+// the best pair of room-disjoint paths has a total length of 11
+var minCostLinks = [][2]string{
+	{"r19", "r3"}, {"r20", "r16"}, {"r15", "r18"}, {"r15", "r21"}, {"r9", "r4"},
+	{"r12", "end"}, {"r2", "r11"}, {"start", "r16"}, {"r14", "r20"}, {"r18", "start"},
+	{"r2", "r12"}, {"r11", "r19"}, {"r2", "r19"}, {"r21", "r14"}, {"r10", "r16"},
+	{"end", "r13"}, {"r21", "r17"}, {"r10", "r13"}, {"r21", "r13"}, {"r11", "r4"},
+	{"r20", "r11"}, {"r5", "r3"},
+}
+
+func totalLength(paths [][]string) int {
+	total := 0
+	for _, path := range paths {
+		total += len(path) - 1
+	}
+	return total
+}
+
+func TestMinCostFlow(t *testing.T) {
+	ek := buildFarm("start", "end", minCostLinks)
+	ek.numAnts = 100
+	ek.EdmondsKarp()
+
+	af := buildFarm("start", "end", minCostLinks)
+	af.numAnts = 100
+	if got := af.MinCostFlow(); got != 2 {
+		t.Fatalf("MinCostFlow() flow level = %v, want 2", got)
+	}
+
+	want := [][]string{
+		{"start", "r18", "r15", "r21", "r13", "end"},
+		{"start", "r16", "r20", "r11", "r2", "r12", "end"},
+	}
+	if !reflect.DeepEqual(af.paths, want) {
+		t.Errorf("MinCostFlow() paths = %v, want %v", af.paths, want)
+	}
+	if got, ekTotal := totalLength(af.paths), totalLength(ek.paths); got != 11 || got >= ekTotal {
+		t.Errorf("MinCostFlow() total length = %v, want 11 and less than Edmonds-Karp's %v", got, ekTotal)
+	}
+
+	moves := af.SimulateAnts()
+	if err := af.VerifyMoves(moves); err != nil {
+		t.Errorf("MinCostFlow() gives an invalid solution: %v", err)
+	}
+	if ekMoves := ek.SimulateAnts(); len(moves) > len(ekMoves) {
+		t.Errorf("MinCostFlow() takes %d turns, Edmonds-Karp only %d", len(moves), len(ekMoves))
+	}
+}
+
+func TestMinCostFlowFewAnts(t *testing.T) {
+	af := buildFarm("start", "end", minCostLinks)
+	af.numAnts = 1
+	if got := af.MinCostFlow(); got != 1 {
+		t.Errorf("MinCostFlow() flow level = %v, want 1", got)
+	}
+	if got := af.paths[0]; len(got) != 5 {
+		t.Errorf("MinCostFlow() path = %v, want a shortest path", got)
+	}
+
+	// No path at all
+	af = buildFarm("start", "end", [][2]string{{"start", "a"}})
+	if got := af.MinCostFlow(); got != 0 || len(af.paths) != 0 {
+		t.Errorf("MinCostFlow() = %v with paths %v, want none", got, af.paths)
+	}
+}
+
+func BenchmarkMinCostFlow(b *testing.B) {
+	benchmarkSolver(b, "mincost")
+}
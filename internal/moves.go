@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Move is a single ant stepping into a room during a turn
+type Move struct {
+	Ant  int
+	Room string
+}
+
+// String formats the move the way it is printed, e.g. L1-room
+func (m Move) String() string {
+	return fmt.Sprintf("L%d-%s", m.Ant, m.Room)
+}
+
+// formatTurns writes the moves of every turn as lines of output, one line
+// per turn
+func formatTurns(turns [][]Move) []string {
+	lines := make([]string, len(turns))
+	var sb strings.Builder
+	for i, moves := range turns {
+		sb.Reset()
+		for j, m := range moves {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteByte('L')
+			sb.WriteString(strconv.Itoa(m.Ant))
+			sb.WriteByte('-')
+			sb.WriteString(m.Room)
+		}
+		lines[i] = sb.String()
+	}
+	return lines
+}
+
+// ParseMoves splits one line of output into the moves made during that turn
+func ParseMoves(line string) ([]Move, error) {
+	fields := strings.Fields(line)
+	moves := make([]Move, 0, len(fields))
+	for _, field := range fields {
+		ant, room, ok := strings.Cut(strings.TrimPrefix(field, "L"), "-")
+		if !ok || !strings.HasPrefix(field, "L") || room == "" {
+			return nil, fmt.Errorf("invalid move %q", field)
+		}
+		num, err := strconv.Atoi(ant)
+		if err != nil || num <= 0 {
+			return nil, fmt.Errorf("invalid ant number in move %q", field)
+		}
+		moves = append(moves, Move{Ant: num, Room: room})
+	}
+	return moves, nil
+}
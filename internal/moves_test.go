@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []Move
+		wantErr bool
+	}{
+		{
+			name: "Single move",
+			line: "L1-room1",
+			want: []Move{{Ant: 1, Room: "room1"}},
+		},
+		{
+			name: "Several moves",
+			line: "L10-h L3-c L4-end",
+			want: []Move{{Ant: 10, Room: "h"}, {Ant: 3, Room: "c"}, {Ant: 4, Room: "end"}},
+		},
+		{
+			name: "Empty line",
+			line: "",
+			want: []Move{},
+		},
+		{
+			name:    "Missing L prefix",
+			line:    "1-room1",
+			wantErr: true,
+		},
+		{
+			name:    "Missing room",
+			line:    "L1-",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid ant number",
+			line:    "Lx-room1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoves(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoves() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMoves() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveString(t *testing.T) {
+	if got := (Move{Ant: 12, Room: "end"}).String(); got != "L12-end" {
+		t.Errorf("Move.String() = %v, want %v", got, "L12-end")
+	}
+}
+
+func TestFormatTurns(t *testing.T) {
+	turns := [][]Move{{{Ant: 2, Room: "a"}, {Ant: 10, Room: "b"}}, {}, {{Ant: 2, Room: "end"}}}
+	want := []string{"L2-a L10-b", "", "L2-end"}
+	if got := formatTurns(turns); !reflect.DeepEqual(got, want) {
+		t.Errorf("formatTurns() = %q, want %q", got, want)
+	}
+}
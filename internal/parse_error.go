@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// ErrorCode identifies the kind of problem found in a farm description
+type ErrorCode string
+
+const (
+	ErrEmptyFile         ErrorCode = "empty-file"
+	ErrInvalidAnts       ErrorCode = "invalid-ants"
+	ErrInvalidRoom       ErrorCode = "invalid-room"
+	ErrInvalidRoomName   ErrorCode = "invalid-room-name"
+	ErrInvalidCoordinate ErrorCode = "invalid-coordinate"
+	ErrMultipleStart     ErrorCode = "multiple-start"
+	ErrMultipleEnd       ErrorCode = "multiple-end"
+	ErrInvalidLink       ErrorCode = "invalid-link"
+	ErrInvalidLength     ErrorCode = "invalid-length"
+	ErrSelfLink          ErrorCode = "self-link"
+	ErrUnknownRoom       ErrorCode = "unknown-room"
+	ErrDuplicateLink     ErrorCode = "duplicate-link"
+	ErrNoStart           ErrorCode = "no-start"
+	ErrNoEnd             ErrorCode = "no-end"
+	ErrNoPath            ErrorCode = "no-path"
+)
+
+// legacyPrefix starts every message the program has always printed
+const legacyPrefix = "ERROR: invalid data format"
+
+// ParseError describes a problem in a farm description and where it is
+type ParseError struct {
+	Line    int       // 1-based line number, 0 when not tied to a line
+	Column  int       // 1-based column of Text within the line
+	Text    string    // The offending text
+	Code    ErrorCode // Kind of problem
+	Message string    // Human readable description, e.g. "link to unknown room"
+}
+
+func newParseError(code ErrorCode, message, text string, column int) *ParseError {
+	return &ParseError{
+		Column:  column,
+		Text:    text,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error renders the legacy message followed by the position when known
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Legacy()
+	}
+	return fmt.Sprintf("%s (line %d, column %d: %q)", e.Legacy(), e.Line, e.Column, e.Text)
+}
+
+// Legacy renders the message without the position, as the CLI has always printed it
+func (e *ParseError) Legacy() string {
+	return fmt.Sprintf("%s, %s", legacyPrefix, e.Message)
+}
+
+// atLine records the line number on err if it is a ParseError
+func atLine(err error, line int) error {
+	var pe *ParseError
+	if errors.As(err, &pe) && pe.Line == 0 {
+		pe.Line = line
+	}
+	return err
+}
+
+// fieldColumns splits line like strings.Fields and also returns the
+// 1-based column where each field starts
+func fieldColumns(line string) ([]string, []int) {
+	var fields []string
+	var columns []int
+	start := -1
+	for i, r := range line {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				fields = append(fields, line[start:i])
+				columns = append(columns, start+1)
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, line[start:])
+		columns = append(columns, start+1)
+	}
+	return fields, columns
+}
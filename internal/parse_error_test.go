@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ParseError
+		wantMsg string
+	}{
+		{
+			name:    "Invalid number of ants",
+			input:   "abc\n",
+			want:    ParseError{Line: 1, Column: 1, Text: "abc", Code: ErrInvalidAnts, Message: "invalid number of ants"},
+			wantMsg: `ERROR: invalid data format, invalid number of ants (line 1, column 1: "abc")`,
+		},
+		{
+			name:    "Invalid y coordinate",
+			input:   "3\n##start\nstart 0 0\nroom1  4 y\n",
+			want:    ParseError{Line: 4, Column: 10, Text: "y", Code: ErrInvalidCoordinate, Message: "invalid y coordinate"},
+			wantMsg: `ERROR: invalid data format, invalid y coordinate (line 4, column 10: "y")`,
+		},
+		{
+			name:  "Link to unknown room",
+			input: "3\n##start\nstart 0 0\n##end\nend 1 1\nstart-nowhere\n",
+			want:  ParseError{Line: 6, Column: 7, Text: "nowhere", Code: ErrUnknownRoom, Message: "link to unknown room"},
+		},
+		{
+			name:  "Missing end room",
+			input: "3\n##start\nstart 0 0\n",
+			want:  ParseError{Code: ErrNoEnd, Message: "no end room found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAntFarm().ParseReader(strings.NewReader(tt.input))
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ParseReader() error = %v, want a *ParseError", err)
+			}
+			if !reflect.DeepEqual(*pe, tt.want) {
+				t.Errorf("ParseReader() error = %+v, want %+v", *pe, tt.want)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+			if !strings.HasPrefix(pe.Legacy(), "ERROR: invalid data format, ") || strings.Contains(pe.Legacy(), "line") {
+				t.Errorf("Legacy() = %q, want the message without position", pe.Legacy())
+			}
+		})
+	}
+}
+
+func TestFieldColumns(t *testing.T) {
+	fields, columns := fieldColumns("  a\tbb  c ")
+	if !reflect.DeepEqual(fields, []string{"a", "bb", "c"}) {
+		t.Errorf("fieldColumns() fields = %v", fields)
+	}
+	if !reflect.DeepEqual(columns, []int{3, 5, 9}) {
+		t.Errorf("fieldColumns() columns = %v", columns)
+	}
+}
@@ -2,12 +2,16 @@ package internal
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// ParseInput reads the farm description from a file
 func (af *AntFarm) ParseInput(filename string) (string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -15,26 +19,82 @@ func (af *AntFarm) ParseInput(filename string) (string, error) {
 	}
 	defer file.Close()
 
+	return af.ParseReader(file)
+}
+
+// ParseReader reads the farm description from r and returns it as read
+func (af *AntFarm) ParseReader(r io.Reader) (string, error) {
+	content, problems, err := af.parse(r, false)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		return "", problems[0]
+	}
+	return content, nil
+}
+
+// Diagnose reads the farm description from r like ParseReader, but keeps
+// going after bad rooms and links so every problem is found in one pass.
+// The problems are sorted by line, with those not tied to a line last
+func (af *AntFarm) Diagnose(r io.Reader) ([]*ParseError, error) {
+	_, problems, err := af.parse(r, true)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		li, lj := problems[i].Line, problems[j].Line
+		if li == 0 || lj == 0 {
+			return lj == 0 && li != 0
+		}
+		return li < lj
+	})
+	return problems, nil
+}
+
+// parse reads the farm from r. Unless collect is set it stops at the first
+// problem found; err is only set when r itself could not be read
+func (af *AntFarm) parse(r io.Reader, collect bool) (content string, problems []*ParseError, err error) {
 	var fileContent strings.Builder
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
+
+	// report records a problem and tells whether to stop parsing
+	report := func(err error, line int) bool {
+		var pe *ParseError
+		if errors.As(atLine(err, line), &pe) {
+			problems = append(problems, pe)
+		}
+		return !collect
+	}
 
 	// Read and validate number of ants
 	if !scanner.Scan() {
-		return "", fmt.Errorf("ERROR: invalid data format, empty file")
+		if err := scanner.Err(); err != nil {
+			return "", nil, fmt.Errorf("error reading input: %v", err)
+		}
+		report(newParseError(ErrEmptyFile, "empty file", "", 1), 0)
+		return "", problems, nil
 	}
-	numAnts, err := strconv.Atoi(scanner.Text())
-	if err != nil || numAnts <= 0 {
-		return "", fmt.Errorf("ERROR: invalid data format, invalid number of ants")
+	lineNum := 1
+	numAnts, convErr := strconv.Atoi(scanner.Text())
+	if convErr != nil || numAnts <= 0 {
+		if report(newParseError(ErrInvalidAnts, "invalid number of ants", scanner.Text(), 1), lineNum) {
+			return "", problems, nil
+		}
+		fileContent.WriteString(scanner.Text() + "\n")
+	} else {
+		af.numAnts = numAnts
+		fileContent.WriteString(fmt.Sprintf("%d\n", numAnts))
 	}
-	af.numAnts = numAnts
-	fileContent.WriteString(fmt.Sprintf("%d\n", numAnts))
 
 	parsingRooms := true
 	var isStart, isEnd bool
 
 	for scanner.Scan() {
+		lineNum++
 		line := scanner.Text()
-		fileContent.WriteString(line + "\n")
+		fileContent.WriteString(line)
+		fileContent.WriteByte('\n')
 
 		if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "##") {
 			continue
@@ -54,31 +114,43 @@ func (af *AntFarm) ParseInput(filename string) (string, error) {
 				parsingRooms = false
 			}
 			if err := af.Parselink(line); err != nil {
-				return "", err
+				if report(err, lineNum) {
+					return "", problems, nil
+				}
 			}
 			continue
 		}
 
 		if parsingRooms && len(line) > 0 {
 			if err := af.ParseRoom(line, isStart, isEnd); err != nil {
-				return "", err
+				if report(err, lineNum) {
+					return "", problems, nil
+				}
 			}
 			isStart = false
 			isEnd = false
 		}
 	}
-	Reading(fileContent.String())
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("error reading input: %v", err)
+	}
 	if af.startRoom == nil {
-		return "", fmt.Errorf("ERROR: invalid data format, no start room found")
+		if report(newParseError(ErrNoStart, "no start room found", "", 0), 0) {
+			return "", problems, nil
+		}
 	}
 	if af.endRoom == nil {
-		return "", fmt.Errorf("ERROR: invalid data format, no end room found")
+		if report(newParseError(ErrNoEnd, "no end room found", "", 0), 0) {
+			return "", problems, nil
+		}
 	}
 
-	startToEnd := af.ValidateStartEndPath()
-	if startToEnd != nil {
-		return "", fmt.Errorf("ERROR: invalid data format, no link from start to end")
+	if af.startRoom != nil && af.endRoom != nil {
+		startToEnd := af.ValidateStartEndPath()
+		if startToEnd != nil {
+			report(newParseError(ErrNoPath, "no link from start to end", "", 0), 0)
+		}
 	}
 
-	return fileContent.String(), nil
+	return fileContent.String(), problems, nil
 }
@@ -169,3 +169,85 @@ func TestAntFarm_ParseInput_FileErrors(t *testing.T) {
 		t.Error("ParseInput() expected error when passing directory")
 	}
 }
+
+func TestAntFarm_ParseReader(t *testing.T) {
+	input := `3
+##start
+start 0 0
+##end
+end 1 1
+start-end`
+
+	af := NewAntFarm()
+	content, err := af.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if content != input+"\n" {
+		t.Errorf("ParseReader() content = %q, want %q", content, input+"\n")
+	}
+	if af.numAnts != 3 || af.startRoom == nil || af.endRoom == nil {
+		t.Errorf("ParseReader() did not set up the farm: %+v", af)
+	}
+
+	if _, err := NewAntFarm().ParseReader(strings.NewReader("")); err == nil {
+		t.Error("ParseReader() expected error for empty input")
+	}
+}
+
+func TestAntFarm_Diagnose(t *testing.T) {
+	input := `0
+##start
+start 0 0
+roomA x 1
+roomB 2 2
+start-roomB
+start-roomB
+roomB-zzz`
+
+	problems, err := NewAntFarm().Diagnose(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Diagnose() unexpected error: %v", err)
+	}
+
+	want := []struct {
+		line int
+		code ErrorCode
+	}{
+		{1, ErrInvalidAnts},
+		{4, ErrInvalidCoordinate},
+		{7, ErrDuplicateLink},
+		{8, ErrUnknownRoom},
+		{0, ErrNoEnd},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Diagnose() found %d problems, want %d: %v", len(problems), len(want), problems)
+	}
+	for i, w := range want {
+		if problems[i].Line != w.line || problems[i].Code != w.code {
+			t.Errorf("Diagnose() problem %d = line %d %s, want line %d %s",
+				i, problems[i].Line, problems[i].Code, w.line, w.code)
+		}
+	}
+
+	// A valid farm has nothing to report
+	problems, err = NewAntFarm().Diagnose(strings.NewReader("1\n##start\na 0 0\n##end\nb 1 1\na-b"))
+	if err != nil || len(problems) != 0 {
+		t.Errorf("Diagnose() = %v, %v, want no problems", problems, err)
+	}
+}
+
+func BenchmarkParseReader(b *testing.B) {
+	for _, topology := range []string{"grid", "layered"} {
+		input := largeFarm(topology)
+		b.Run(topology, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				af := NewAntFarm()
+				if _, err := af.ParseReader(strings.NewReader(input)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
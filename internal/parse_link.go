@@ -1,34 +1,66 @@
 package internal
 
 import (
-	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
+// MaxLinkLength is the most turns a tunnel can take to go through
+const MaxLinkLength = math.MaxInt16
+
+// Parselink reads a tunnel between two rooms, "a-b", optionally followed
+// by the number of turns ants take to go through it, "a-b 3"
 func (af *AntFarm) Parselink(line string) error {
-	parts := strings.Split(line, "-")
+	rooms, lengthText, hasLength := strings.Cut(line, " ")
+	length := 1
+	if hasLength {
+		n, err := strconv.Atoi(lengthText)
+		if err != nil || n < 1 || n > MaxLinkLength {
+			return newParseError(ErrInvalidLength, "invalid link length", lengthText, len(rooms)+2)
+		}
+		length = n
+	}
+
+	parts := strings.Split(rooms, "-")
 	// Add in Parselink
 	if parts[0] == parts[1] {
-		return fmt.Errorf("ERROR: invalid data format, room cannot link to itself")
+		return newParseError(ErrSelfLink, "room cannot link to itself", line, 1)
 	}
 	if len(parts) != 2 {
-		return fmt.Errorf("ERROR: invalid data format, invalid link format")
+		return newParseError(ErrInvalidLink, "invalid link format", line, 1)
 	}
 
 	room1, exists1 := af.rooms[parts[0]]
 	room2, exists2 := af.rooms[parts[1]]
 
-	if !exists1 || !exists2 {
-		return fmt.Errorf("ERROR: invalid data format, link to unknown room")
+	if !exists1 {
+		return newParseError(ErrUnknownRoom, "link to unknown room", parts[0], 1)
+	}
+	if !exists2 {
+		return newParseError(ErrUnknownRoom, "link to unknown room", parts[1], len(parts[0])+2)
 	}
 
-	for _, conn := range room1.connections {
-		if conn == room2 {
-			return fmt.Errorf("ERROR: invalid data format, duplicate link")
-		}
+	// Looked up by key so that linking rooms with many tunnels stays
+	// cheap
+	key := linkKey(room1.name, room2.name)
+	if _, ok := af.linked[key]; ok {
+		return newParseError(ErrDuplicateLink, "duplicate link", line, 1)
+	}
+	if af.linked == nil {
+		af.linked = make(map[[2]string]struct{})
 	}
+	af.linked[key] = struct{}{}
 
 	room1.connections = append(room1.connections, room2)
 	room2.connections = append(room2.connections, room1)
+	af.links = append(af.links, [2]string{room1.name, room2.name})
+	if length > 1 {
+		if af.lengths == nil {
+			af.lengths = make(map[[2]string]int)
+		}
+		af.lengths[key] = length
+	}
+	af.numbered = nil
 	return nil
 }
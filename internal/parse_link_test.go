@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -75,6 +76,8 @@ func TestParseLink(t *testing.T) {
 				}
 				start.connections = append(start.connections, room1)
 				room1.connections = append(room1.connections, start)
+				af.links = [][2]string{{"start", "room1"}}
+				af.linked = map[[2]string]struct{}{linkKey("start", "room1"): {}}
 				return af
 			},
 			wantErr: true,
@@ -144,3 +147,51 @@ func TestParseLink(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLinkLength(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    int
+		wantErr bool
+	}{
+		{line: "start-room1", want: 1},
+		{line: "start-room1 1", want: 1},
+		{line: "start-room1 3", want: 3},
+		{line: "room1-start 32767", want: 32767},
+		{line: "start-room1 0", wantErr: true},
+		{line: "start-room1 -2", wantErr: true},
+		{line: "start-room1 32768", wantErr: true},
+		{line: "start-room1 three", wantErr: true},
+		{line: "start-room1 ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			af := NewAntFarm()
+			af.rooms = map[string]*Room{
+				"start": {name: "start"},
+				"room1": {name: "room1"},
+			}
+			err := af.Parselink(tt.line)
+			if tt.wantErr {
+				var pe *ParseError
+				if !errors.As(err, &pe) || pe.Code != ErrInvalidLength {
+					t.Fatalf("Parselink() error = %v, want %s", err, ErrInvalidLength)
+				}
+				if len(af.links) != 0 {
+					t.Errorf("Parselink() added link %v despite the error", af.links)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parselink() unexpected error: %v", err)
+			}
+			if got := af.LinkLength("start", "room1"); got != tt.want {
+				t.Errorf("LinkLength(start, room1) = %d, want %d", got, tt.want)
+			}
+			if got := af.LinkLength("room1", "start"); got != tt.want {
+				t.Errorf("LinkLength(room1, start) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,30 +1,29 @@
 package internal
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 )
 
 func (af *AntFarm) ParseRoom(line string, isStart bool, isEnd bool) error {
-	parts := strings.Fields(line)
+	parts, columns := fieldColumns(line)
 	if len(parts) != 3 {
-		return fmt.Errorf("ERROR: invalid data format, invalid room definition")
+		return newParseError(ErrInvalidRoom, "invalid room definition", line, 1)
 	}
 
 	name := parts[0]
 	if strings.HasPrefix(name, "L") || strings.HasPrefix(name, "#") {
-		return fmt.Errorf("ERROR: invalid data format, invalid room name")
+		return newParseError(ErrInvalidRoomName, "invalid room name", name, columns[0])
 	}
 
 	x, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return fmt.Errorf("ERROR: invalid data format, invalid x coordinate")
+		return newParseError(ErrInvalidCoordinate, "invalid x coordinate", parts[1], columns[1])
 	}
 
 	y, err := strconv.Atoi(parts[2])
 	if err != nil {
-		return fmt.Errorf("ERROR: invalid data format, invalid y coordinate")
+		return newParseError(ErrInvalidCoordinate, "invalid y coordinate", parts[2], columns[2])
 	}
 
 	room := &Room{
@@ -38,17 +37,19 @@ func (af *AntFarm) ParseRoom(line string, isStart bool, isEnd bool) error {
 
 	if isStart {
 		if af.startRoom != nil {
-			return fmt.Errorf("ERROR: invalid data format, multiple start rooms")
+			return newParseError(ErrMultipleStart, "multiple start rooms", name, columns[0])
 		}
 		af.startRoom = room
 	}
 	if isEnd {
 		if af.endRoom != nil {
-			return fmt.Errorf("ERROR: invalid data format, multiple end rooms")
+			return newParseError(ErrMultipleEnd, "multiple end rooms", name, columns[0])
 		}
 		af.endRoom = room
 	}
 
 	af.rooms[name] = room
+	af.order = append(af.order, name)
+	af.numbered = nil
 	return nil
 }
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRegressionCorpus solves every farm in testfarms/regression and
+// requires a valid solution taking no more turns than the number stored
+// next to it in the matching .want file
+func TestRegressionCorpus(t *testing.T) {
+	farms, err := filepath.Glob("testfarms/regression/*.txt")
+	if err != nil || len(farms) == 0 {
+		t.Fatalf("no regression farms found: %v", err)
+	}
+
+	for _, farmFile := range farms {
+		name := strings.TrimSuffix(filepath.Base(farmFile), ".txt")
+		t.Run(name, func(t *testing.T) {
+			want, err := os.ReadFile(strings.TrimSuffix(farmFile, ".txt") + ".want")
+			if err != nil {
+				t.Fatalf("missing expected turn count: %v", err)
+			}
+			wantTurns, err := strconv.Atoi(strings.TrimSpace(string(want)))
+			if err != nil {
+				t.Fatalf("invalid expected turn count %q", want)
+			}
+
+			af := NewAntFarm()
+			if _, err := af.ParseInput(farmFile); err != nil {
+				t.Fatalf("ParseInput() unexpected error: %v", err)
+			}
+			af.EdmondsKarp()
+			moves := af.SimulateAnts()
+
+			if len(moves) > wantTurns {
+				t.Errorf("solution takes %d turns, want at most %d", len(moves), wantTurns)
+			}
+			if bound := af.LowerBound(); bound > len(moves) {
+				t.Errorf("lower bound %d is above the %d turns found", bound, len(moves))
+			}
+			if err := af.VerifyMoves(moves); err != nil {
+				t.Errorf("invalid solution: %v", err)
+			}
+		})
+	}
+}
@@ -1,9 +1,8 @@
 package internal
 
 import (
-	"fmt"
+	"context"
 	"sort"
-	"strings"
 )
 
 func (af *AntFarm) SimulateAnts() []string {
@@ -12,16 +11,59 @@ func (af *AntFarm) SimulateAnts() []string {
 	}
 
 	// Sort paths by length
-	sort.Slice(af.paths, func(i, j int) bool {
-		return len(af.paths[i]) < len(af.paths[j])
+	sort.SliceStable(af.paths, func(i, j int) bool {
+		return af.pathLength(af.paths[i]) < af.pathLength(af.paths[j])
 	})
 
+	_, turns, _ := af.schedule(context.Background(), af.paths, af.numAnts)
+	return formatTurns(turns)
+}
+
+// schedule spreads the ants over paths sorted by length and generates the
+// moves, returning how many ants go down each path along with the moves
+// made on every turn. It returns the error from stopped when ctx is done
+// first
+func (af *AntFarm) schedule(ctx context.Context, sortedPaths [][]string, numAnts int) ([]int, [][]Move, error) {
+	if ctx.Err() != nil {
+		return nil, nil, stopped(ctx)
+	}
 	// Calculate optimal distribution of ants
-	paths := calculatePathsInfo(af.paths)
-	optimalTurns, finalDistribution := findOptimalTurns(paths, af.numAnts)
+	paths := calculatePathsInfo(af.expandPaths(sortedPaths))
+	optimalTurns, finalDistribution := findOptimalTurns(paths, numAnts)
+
+	ants := make([]int, len(finalDistribution))
+	for i, p := range finalDistribution {
+		ants[i] = p.capacity
+	}
 
 	// Generate and return moves
-	return generateMoves(finalDistribution, optimalTurns, af.numAnts, af.endRoom.name)
+	turns, err := generateMoves(ctx, finalDistribution, optimalTurns, numAnts, af.endRoom.name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ants, turns, nil
+}
+
+// expandPaths returns the paths with an empty room name added for every
+// extra turn an ant spends inside a tunnel, so that each step along an
+// expanded path takes one turn. Paths are returned as they are when every
+// tunnel takes one turn
+func (af *AntFarm) expandPaths(paths [][]string) [][]string {
+	if len(af.lengths) == 0 {
+		return paths
+	}
+	expanded := make([][]string, len(paths))
+	for i, path := range paths {
+		steps := []string{path[0]}
+		for k := 1; k < len(path); k++ {
+			for n := af.LinkLength(path[k-1], path[k]); n > 1; n-- {
+				steps = append(steps, "")
+			}
+			steps = append(steps, path[k])
+		}
+		expanded[i] = steps
+	}
+	return expanded
 }
 
 func calculatePathsInfo(paths [][]string) []PathInfo {
@@ -36,8 +78,11 @@ func calculatePathsInfo(paths [][]string) []PathInfo {
 	return pathsInfo
 }
 
+// findOptimalTurns finds the fewest turns the paths can move numAnts ants
+// in, and how many ants go down each. It binary searches the turn count,
+// so it takes O(len(paths) * log numAnts) and needs no ctx to stop it
 func findOptimalTurns(paths []PathInfo, numAnts int) (int, []PathInfo) {
-	left, right := 1, numAnts+len(paths[0].path)-1
+	left, right := 1, numAnts+paths[0].length
 	var optimalTurns int
 	var finalDistribution []PathInfo
 
@@ -75,68 +120,98 @@ func findOptimalTurns(paths []PathInfo, numAnts int) (int, []PathInfo) {
 	return optimalTurns, finalDistribution
 }
 
-func generateMoves(paths []PathInfo, optimalTurns, numAnts int, endRoomName string) []string {
-	moves := make([]string, 0)
+// antState is where an ant on its way is: the path it was sent down and
+// how far along the expanded path it has got
+type antState struct {
+	path     int // Index of the path in the distribution
+	position int // Index of its step in the path
+}
+
+// generateMoves makes the moves of every turn, checking ctx once a turn
+// since a farm with many ants can take a long time
+func generateMoves(ctx context.Context, paths []PathInfo, optimalTurns, numAnts int, endRoomName string) ([][]Move, error) {
+	turns := make([][]Move, 0, optimalTurns)
 	antNum := 1
-	antStates := make(map[int]struct {
-		pathIndex int
-		position  int
-	})
+	antStates := make(map[int]antState)
 
 	for turn := 0; turn < optimalTurns; turn++ {
-		currentMoves := make([]string, 0)
+		if ctx.Err() != nil {
+			return nil, stopped(ctx)
+		}
+		currentMoves := make([]Move, 0)
 		occupied := make(map[string]bool)
 
 		// Move existing ants
-		moveExistingAnts(&antStates, paths, occupied, &currentMoves, endRoomName)
-
-		// Start new ants
-		startNewAnts(paths, &antStates, &antNum, occupied, &currentMoves)
+		moveExistingAnts(antStates, paths, occupied, &currentMoves, endRoomName)
 
-		if len(currentMoves) > 0 {
-			sort.Strings(currentMoves)
-			moves = append(moves, strings.Join(currentMoves, " "))
+		// Ants in the end room never move again, so stop going through them
+		for ant, state := range antStates {
+			if state.position == len(paths[state.path].path)-1 {
+				delete(antStates, ant)
+			}
 		}
+
+		// Start new ants, numbered after every ant already on the way, so
+		// the moves stay in ant order. While every ant on the way is inside
+		// a long tunnel nobody arrives anywhere, and the turn is empty
+		startNewAnts(paths, antStates, &antNum, occupied, &currentMoves)
+		turns = append(turns, currentMoves)
 	}
 
-	return moves
+	return turns, nil
 }
 
-func moveExistingAnts(antStates *map[int]struct {
-	pathIndex int
-	position  int
-}, paths []PathInfo, occupied map[string]bool, currentMoves *[]string, endRoomName string) {
-	for ant, state := range *antStates {
-		path := paths[state.pathIndex].path
+func moveExistingAnts(antStates map[int]antState, paths []PathInfo, occupied map[string]bool, currentMoves *[]Move, endRoomName string) {
+	// Move ants in order so the result doesn't depend on map iteration
+	ants := make([]int, 0, len(antStates))
+	for ant := range antStates {
+		ants = append(ants, ant)
+	}
+	sort.Ints(ants)
+
+	for _, ant := range ants {
+		state := antStates[ant]
+		path := paths[state.path].path
 		if state.position < len(path)-1 {
 			nextRoom := path[state.position+1]
+			if nextRoom == "" || path[state.position] == "" {
+				// Inside a tunnel nothing can get in the way, and only
+				// arriving at the other end is a move
+				state.position++
+				antStates[ant] = state
+				if nextRoom != "" {
+					if nextRoom != endRoomName {
+						occupied[nextRoom] = true
+					}
+					*currentMoves = append(*currentMoves, Move{Ant: ant, Room: nextRoom})
+				}
+				continue
+			}
 			if !occupied[nextRoom] || nextRoom == endRoomName {
 				// Move ant forward
 				state.position++
-				(*antStates)[ant] = state
+				antStates[ant] = state
 				if nextRoom != endRoomName {
 					occupied[nextRoom] = true
 				}
-				*currentMoves = append(*currentMoves, fmt.Sprintf("L%d-%s", ant, nextRoom))
+				*currentMoves = append(*currentMoves, Move{Ant: ant, Room: nextRoom})
 			}
 		}
 	}
 }
 
-func startNewAnts(paths []PathInfo, antStates *map[int]struct {
-	pathIndex int
-	position  int
-}, antNum *int, occupied map[string]bool, currentMoves *[]string) {
+func startNewAnts(paths []PathInfo, antStates map[int]antState, antNum *int, occupied map[string]bool, currentMoves *[]Move) {
 	for i := range paths {
 		if paths[i].capacity > 0 {
 			nextRoom := paths[i].path[1]
 			if !occupied[nextRoom] {
-				(*antStates)[*antNum] = struct {
-					pathIndex int
-					position  int
-				}{i, 1}
-				occupied[nextRoom] = true
-				*currentMoves = append(*currentMoves, fmt.Sprintf("L%d-%s", *antNum, nextRoom))
+				antStates[*antNum] = antState{path: i, position: 1}
+				// Going into a long tunnel, the ant only shows up once it
+				// comes out
+				if nextRoom != "" {
+					occupied[nextRoom] = true
+					*currentMoves = append(*currentMoves, Move{Ant: *antNum, Room: nextRoom})
+				}
 				*antNum++ // Increment ant number
 				paths[i].capacity--
 			}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -133,7 +134,7 @@ func TestGenerateMoves(t *testing.T) {
 		turns       int
 		numAnts     int
 		endRoomName string
-		expected    []string
+		expected    [][]Move
 	}{
 		{
 			name: "Single ant, single path",
@@ -147,7 +148,7 @@ func TestGenerateMoves(t *testing.T) {
 			turns:       2,
 			numAnts:     1,
 			endRoomName: "end",
-			expected:    []string{"L1-room1", "L1-end"},
+			expected:    [][]Move{{{1, "room1"}}, {{1, "end"}}},
 		},
 		{
 			name: "Multiple ants, multiple paths",
@@ -166,17 +167,20 @@ func TestGenerateMoves(t *testing.T) {
 			turns:       3,
 			numAnts:     3,
 			endRoomName: "end",
-			expected: []string{
-				"L1-room1 L2-room2",
-				"L1-end L2-end L3-room1",
-				"L3-end",
+			expected: [][]Move{
+				{{1, "room1"}, {2, "room2"}},
+				{{1, "end"}, {2, "end"}, {3, "room1"}},
+				{{3, "end"}},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := generateMoves(tt.paths, tt.turns, tt.numAnts, tt.endRoomName)
+			result, err := generateMoves(context.Background(), tt.paths, tt.turns, tt.numAnts, tt.endRoomName)
+			if err != nil {
+				t.Fatalf("generateMoves() unexpected error: %v", err)
+			}
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf("generateMoves() = %v, want %v", result, tt.expected)
 			}
@@ -193,35 +197,22 @@ func TestMoveExistingAnts(t *testing.T) {
 		},
 	}
 
-	antStates := make(map[int]struct {
-		pathIndex int
-		position  int
-	})
-	antStates[1] = struct {
-		pathIndex int
-		position  int
-	}{0, 1}
+	antStates := map[int]antState{1: {0, 1}}
 
 	occupied := make(map[string]bool)
-	var currentMoves []string
+	var currentMoves []Move
 
 	tests := []struct {
 		name           string
 		endRoomName    string
-		expectedMoves  []string
-		expectedStates map[int]struct {
-			pathIndex int
-			position  int
-		}
+		expectedMoves  []Move
+		expectedStates map[int]antState
 	}{
 		{
 			name:          "Move ant to end",
 			endRoomName:   "end",
-			expectedMoves: []string{"L1-end"},
-			expectedStates: map[int]struct {
-				pathIndex int
-				position  int
-			}{
+			expectedMoves: []Move{{1, "end"}},
+			expectedStates: map[int]antState{
 				1: {0, 2},
 			},
 		},
@@ -229,7 +220,7 @@ func TestMoveExistingAnts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			moveExistingAnts(&antStates, paths, occupied, &currentMoves, tt.endRoomName)
+			moveExistingAnts(antStates, paths, occupied, &currentMoves, tt.endRoomName)
 
 			if !reflect.DeepEqual(currentMoves, tt.expectedMoves) {
 				t.Errorf("moveExistingAnts() moves = %v, want %v", currentMoves, tt.expectedMoves)
@@ -250,30 +241,21 @@ func TestStartNewAnts(t *testing.T) {
 		},
 	}
 
-	antStates := make(map[int]struct {
-		pathIndex int
-		position  int
-	})
+	antStates := make(map[int]antState)
 	antNum := 1
 	occupied := make(map[string]bool)
-	var currentMoves []string
+	var currentMoves []Move
 
 	tests := []struct {
 		name           string
-		expectedMoves  []string
-		expectedStates map[int]struct {
-			pathIndex int
-			position  int
-		}
+		expectedMoves  []Move
+		expectedStates map[int]antState
 		expectedAntNum int
 	}{
 		{
 			name:          "Start new ant",
-			expectedMoves: []string{"L1-room1"},
-			expectedStates: map[int]struct {
-				pathIndex int
-				position  int
-			}{
+			expectedMoves: []Move{{1, "room1"}},
+			expectedStates: map[int]antState{
 				1: {0, 1},
 			},
 			expectedAntNum: 2,
@@ -282,7 +264,7 @@ func TestStartNewAnts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			startNewAnts(paths, &antStates, &antNum, occupied, &currentMoves)
+			startNewAnts(paths, antStates, &antNum, occupied, &currentMoves)
 
 			if !reflect.DeepEqual(currentMoves, tt.expectedMoves) {
 				t.Errorf("startNewAnts() moves = %v, want %v", currentMoves, tt.expectedMoves)
@@ -356,3 +338,22 @@ func TestSimulateAnts(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulateAntsLengths(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "end"}})
+	af.lengths = map[[2]string]int{linkKey("start", "a"): 3}
+	af.numAnts = 2
+	af.paths = [][]string{{"start", "a", "end"}}
+
+	if got, want := af.expandPaths(af.paths), [][]string{{"start", "", "", "a", "end"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expandPaths() = %q, want %q", got, want)
+	}
+	// Nothing is written until the first ant comes out of the tunnel
+	want := []string{"", "", "L1-a", "L1-end L2-a", "L2-end"}
+	if got := af.SimulateAnts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SimulateAnts() = %q, want %q", got, want)
+	}
+	if err := af.VerifyMoves(want); err != nil {
+		t.Errorf("VerifyMoves() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AntState is where one ant is at some turn of a Simulation
+type AntState struct {
+	Ant     int      // Ant number, from 1
+	Path    int      // Index of the path it takes in the schedule's Paths, -1 if none
+	Route   []string // Every room it goes through, start first. Shared, don't modify
+	Room    string   // Room it is in, or last was while inside a tunnel
+	Heading string   // Room at the other end of the tunnel it is inside, "" if none
+	Through float64  // How far through that tunnel it is, from 0 to 1
+	Arrived bool     // Whether Room is the end room
+	Arrival int      // Turn it reaches the end room, 0 if it never does
+}
+
+// Simulation replays a schedule one turn at a time, keeping track of
+// where every ant is. It works from the moves alone, so it follows ants
+// that wait on the way as well as ants sent down fixed paths
+type Simulation struct {
+	start, end string
+	paths      [][]string
+	turns      [][]Move
+	routes     [][]string // Rooms each ant goes through, start first
+	moved      [][]int    // Turn of each move of each ant, in order
+	left       [][]int    // Turn each ant went into the tunnel for each move
+	path       []int      // Path each ant takes, -1 if none
+	turn       int        // Turns played so far
+	pos        []int      // Index in its route of the room each ant is in
+}
+
+// Simulate replays a schedule found for the farm, starting before the
+// first turn with every ant in the start room
+func (af *AntFarm) Simulate(s *Schedule) (*Simulation, error) {
+	return af.NewSimulation(s.Paths, s.Turns)
+}
+
+// NewSimulation replays the moves made on every turn, the ants going
+// down the given paths
+func (af *AntFarm) NewSimulation(paths [][]string, turns [][]Move) (*Simulation, error) {
+	if af.startRoom == nil || af.endRoom == nil {
+		return nil, fmt.Errorf("the farm needs a start and an end room")
+	}
+	sim := &Simulation{
+		start:  af.startRoom.name,
+		end:    af.endRoom.name,
+		paths:  paths,
+		turns:  turns,
+		routes: make([][]string, af.numAnts),
+		moved:  make([][]int, af.numAnts),
+		left:   make([][]int, af.numAnts),
+		path:   make([]int, af.numAnts),
+		pos:    make([]int, af.numAnts),
+	}
+	for i := range sim.routes {
+		sim.routes[i] = []string{sim.start}
+	}
+	for i, moves := range turns {
+		for _, m := range moves {
+			if m.Ant < 1 || m.Ant > af.numAnts {
+				return nil, &VerifyError{Turn: i + 1, Ant: m.Ant, Reason: fmt.Sprintf("there are only %d ants", af.numAnts)}
+			}
+			route := sim.routes[m.Ant-1]
+			length := af.LinkLength(route[len(route)-1], m.Room)
+			sim.routes[m.Ant-1] = append(route, m.Room)
+			sim.moved[m.Ant-1] = append(sim.moved[m.Ant-1], i+1)
+			sim.left[m.Ant-1] = append(sim.left[m.Ant-1], i+2-length)
+		}
+	}
+
+	pathIndex := make(map[string]int, len(paths))
+	for i, path := range paths {
+		pathIndex[strings.Join(path, " ")] = i
+	}
+	for i, route := range sim.routes {
+		sim.path[i] = -1
+		if p, ok := pathIndex[strings.Join(route, " ")]; ok {
+			sim.path[i] = p
+		}
+	}
+	return sim, nil
+}
+
+// Paths returns the paths the ants were sent down, which AntState.Path
+// indexes
+func (s *Simulation) Paths() [][]string {
+	return s.paths
+}
+
+// Turn returns the number of turns played so far
+func (s *Simulation) Turn() int {
+	return s.turn
+}
+
+// Turns returns the number of turns the schedule takes
+func (s *Simulation) Turns() int {
+	return len(s.turns)
+}
+
+// Done reports whether every turn has been played
+func (s *Simulation) Done() bool {
+	return s.turn == len(s.turns)
+}
+
+// Moves returns the moves made on a turn, from 1 to Turns()
+func (s *Simulation) Moves(turn int) []Move {
+	if turn < 1 || turn > len(s.turns) {
+		return nil
+	}
+	return s.turns[turn-1]
+}
+
+// Step plays the next turn and returns the moves made, or nil once every
+// turn has been played
+func (s *Simulation) Step() []Move {
+	if s.Done() {
+		return nil
+	}
+	s.turn++
+	moves := s.turns[s.turn-1]
+	for _, m := range moves {
+		s.pos[m.Ant-1]++
+	}
+	return moves
+}
+
+// Seek moves straight to the state after the given turn, either forwards
+// or backwards. Turn 0 is before anything has moved
+func (s *Simulation) Seek(turn int) {
+	s.turn = max(0, min(turn, len(s.turns)))
+	for i, moved := range s.moved {
+		s.pos[i] = sort.SearchInts(moved, s.turn+1)
+	}
+}
+
+// Ant returns the state of ant n, counting from 1. ok is false when there
+// is no such ant
+func (s *Simulation) Ant(n int) (state AntState, ok bool) {
+	if n < 1 || n > len(s.routes) {
+		return AntState{}, false
+	}
+	return s.ant(n - 1), true
+}
+
+// ant returns the state of the ant at index i
+func (s *Simulation) ant(i int) AntState {
+	route := s.routes[i]
+	state := AntState{
+		Ant:   i + 1,
+		Path:  s.path[i],
+		Route: route,
+		Room:  route[s.pos[i]],
+	}
+	if k := s.pos[i]; k < len(s.moved[i]) && s.left[i][k] <= s.turn {
+		state.Heading = route[k+1]
+		state.Through = float64(s.turn-s.left[i][k]+1) / float64(s.moved[i][k]-s.left[i][k]+1)
+	}
+	state.Arrived = state.Room == s.end
+	if route[len(route)-1] == s.end {
+		state.Arrival = s.moved[i][len(s.moved[i])-1]
+	}
+	return state
+}
+
+// Ants returns the state of every ant, in ant order
+func (s *Simulation) Ants() []AntState {
+	ants := make([]AntState, len(s.routes))
+	for i := range ants {
+		ants[i] = s.ant(i)
+	}
+	return ants
+}
+
+// Occupied returns the ant in each room holding one. The start and end
+// rooms can hold any number of ants, so they are left out, and so are
+// ants inside a tunnel
+func (s *Simulation) Occupied() map[string]int {
+	occupied := make(map[string]int)
+	for i, route := range s.routes {
+		if k := s.pos[i]; k < len(s.moved[i]) && s.left[i][k] <= s.turn {
+			continue
+		}
+		if room := route[s.pos[i]]; room != s.start && room != s.end {
+			occupied[room] = i + 1
+		}
+	}
+	return occupied
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSimulation(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "end"}, {"start", "b"}, {"b", "c"}, {"c", "end"}})
+	af.numAnts = 3
+	schedule := &Schedule{
+		Paths: [][]string{{"start", "a", "end"}, {"start", "b", "c", "end"}},
+		Ants:  []int{2, 1},
+		Turns: [][]Move{{{1, "a"}, {2, "b"}}, {{1, "end"}, {2, "c"}, {3, "a"}}, {{2, "end"}, {3, "end"}}},
+	}
+	sim, err := af.Simulate(schedule)
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+	if sim.Turn() != 0 || sim.Turns() != 3 || sim.Done() {
+		t.Fatalf("new simulation at turn %d of %d, want 0 of 3", sim.Turn(), sim.Turns())
+	}
+	if got := sim.Occupied(); len(got) != 0 {
+		t.Errorf("Occupied() before the first turn = %v, want none", got)
+	}
+
+	if got := sim.Step(); !reflect.DeepEqual(got, []Move{{1, "a"}, {2, "b"}}) {
+		t.Errorf("Step() = %v, want L1-a L2-b", got)
+	}
+	if got := sim.Occupied(); !reflect.DeepEqual(got, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("Occupied() after turn 1 = %v", got)
+	}
+	want := AntState{Ant: 2, Path: 1, Route: []string{"start", "b", "c", "end"}, Room: "b", Arrival: 3}
+	if got, _ := sim.Ant(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ant(2) = %+v, want %+v", got, want)
+	}
+	if got, _ := sim.Ant(3); got.Room != "start" || got.Path != 0 || got.Arrival != 3 {
+		t.Errorf("Ant(3) = %+v, want in start, on path 0, arriving on turn 3", got)
+	}
+
+	sim.Step()
+	if got := sim.Occupied(); !reflect.DeepEqual(got, map[string]int{"a": 3, "c": 2}) {
+		t.Errorf("Occupied() after turn 2 = %v, want a and c", got)
+	}
+	if got, _ := sim.Ant(1); !got.Arrived || got.Room != "end" {
+		t.Errorf("Ant(1) after turn 2 = %+v, want arrived", got)
+	}
+	sim.Step()
+	if !sim.Done() || sim.Step() != nil {
+		t.Errorf("simulation not done after the last turn")
+	}
+	for _, ant := range sim.Ants() {
+		if !ant.Arrived {
+			t.Errorf("ant %d ends in %s, want end", ant.Ant, ant.Room)
+		}
+	}
+
+	// Seeking back gives the same state as stepping there
+	sim.Seek(1)
+	if got := sim.Occupied(); sim.Turn() != 1 || !reflect.DeepEqual(got, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("Seek(1) at turn %d, occupied %v", sim.Turn(), got)
+	}
+	sim.Seek(-1)
+	if got, _ := sim.Ant(1); sim.Turn() != 0 || got.Room != "start" {
+		t.Errorf("Seek(-1) at turn %d with ant 1 in %s, want turn 0 in start", sim.Turn(), got.Room)
+	}
+	sim.Seek(10)
+	if !sim.Done() {
+		t.Errorf("Seek(10) at turn %d, want done", sim.Turn())
+	}
+}
+
+func TestSimulationWaiting(t *testing.T) {
+	// Ants from the exact solver can wait on the way, so they are
+	// followed by their moves rather than by their path
+	af := buildFarm("start", "end", minCostLinks)
+	af.numAnts = 8
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+		if err != nil {
+			t.Fatalf("%s: Solve() unexpected error: %v", name, err)
+		}
+		sim, err := af.Simulate(schedule)
+		if err != nil {
+			t.Fatalf("%s: Simulate() unexpected error: %v", name, err)
+		}
+		for !sim.Done() {
+			moves := sim.Step()
+			for _, m := range moves {
+				if got, _ := sim.Ant(m.Ant); got.Room != m.Room {
+					t.Errorf("%s turn %d: ant %d in %s, want %s", name, sim.Turn(), m.Ant, got.Room, m.Room)
+				}
+			}
+			held := 0
+			for _, ant := range sim.Ants() {
+				if ant.Room != "start" && ant.Room != "end" {
+					held++
+				}
+			}
+			if len(sim.Occupied()) != held {
+				t.Errorf("%s turn %d: %d rooms occupied by %d ants", name, sim.Turn(), len(sim.Occupied()), held)
+			}
+		}
+		for _, ant := range sim.Ants() {
+			if ant.Path < 0 || ant.Arrival < 1 || ant.Arrival > sim.Turns() {
+				t.Errorf("%s: ant %d on path %d arriving on turn %d", name, ant.Ant, ant.Path, ant.Arrival)
+			}
+		}
+	}
+}
+
+func TestSimulationErrors(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "end"}})
+	af.numAnts = 1
+
+	var ve *VerifyError
+	if _, err := af.Simulate(&Schedule{Turns: [][]Move{{{1, "end"}}, {{2, "end"}}}}); !errors.As(err, &ve) || ve.Turn != 2 || ve.Ant != 2 {
+		t.Errorf("Simulate() with too many ants = %v, want turn 2 ant 2", err)
+	}
+	if _, err := af.Simulate(&Schedule{Turns: [][]Move{{{0, "end"}}}}); !errors.As(err, &ve) || ve.Turn != 1 || ve.Ant != 0 {
+		t.Errorf("Simulate() with ant 0 = %v, want turn 1 ant 0", err)
+	}
+}
+
+func TestSimulationLengths(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "end"}})
+	af.lengths = map[[2]string]int{linkKey("start", "a"): 4}
+	af.numAnts = 1
+	schedule := &Schedule{
+		Paths: [][]string{{"start", "a", "end"}},
+		Ants:  []int{1},
+		Turns: [][]Move{{}, {}, {}, {{1, "a"}}, {{1, "end"}}},
+	}
+	sim, err := af.Simulate(schedule)
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+
+	sim.Seek(2)
+	if got, _ := sim.Ant(1); got.Room != "start" || got.Heading != "a" || got.Through != 0.5 {
+		t.Errorf("Ant(1) after turn 2 = %+v, want half way from start to a", got)
+	}
+	if got := sim.Occupied(); len(got) != 0 {
+		t.Errorf("Occupied() with the ant inside a tunnel = %v, want none", got)
+	}
+	sim.Seek(4)
+	if got, _ := sim.Ant(1); got.Room != "a" || got.Heading != "" || got.Through != 0 {
+		t.Errorf("Ant(1) after turn 4 = %+v, want in a", got)
+	}
+}
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Schedule is what a Solver produces for a farm
+type Schedule struct {
+	Paths      [][]string // Room names from start to end, shortest first
+	Ants       []int      // Number of ants sent down each path
+	Turns      [][]Move   // Moves made during each turn, in ant order
+	LowerBound int        // Fewest turns any solution could possibly take
+	Warnings   []string   // Anything the user should know about how it was found
+	Partial    bool       // The search was stopped before it finished
+}
+
+// Lines formats the moves of every turn as the lines of Lx-y moves
+// lem-in prints
+func (s *Schedule) Lines() []string {
+	return formatTurns(s.Turns)
+}
+
+// Solver finds how to move numAnts ants from the start room to the end
+// room. Solvers only read the farm, so several can run on the same one.
+// When ctx is done before the search finishes, a solver returns the best
+// schedule found so far with Partial set, or the error from stopped if it
+// has none yet. When it is done after that, while the moves are being
+// written out, the error from stopped is returned too. Spreading the ants
+// over a path set with findOptimalTurns doesn't check ctx: it is a binary
+// search over the turn count, a few dozen passes over the paths however
+// many ants there are
+type Solver interface {
+	Solve(ctx context.Context, af *AntFarm, numAnts int) (*Schedule, error)
+}
+
+// SolverFunc lets an ordinary function be used as a Solver
+type SolverFunc func(ctx context.Context, af *AntFarm, numAnts int) (*Schedule, error)
+
+// Solve calls f(ctx, af, numAnts)
+func (f SolverFunc) Solve(ctx context.Context, af *AntFarm, numAnts int) (*Schedule, error) {
+	return f(ctx, af, numAnts)
+}
+
+// stopped returns the error for a search ctx ended before anything was
+// found. errors.Is matches it against context.Canceled and
+// context.DeadlineExceeded
+func stopped(ctx context.Context) error {
+	return fmt.Errorf("solving stopped: %w", ctx.Err())
+}
+
+var solvers = make(map[string]Solver)
+
+// RegisterSolver makes a solver available by name. It panics if the name
+// is already taken, since that can only be a programming error
+func RegisterSolver(name string, s Solver) {
+	if _, exists := solvers[name]; exists {
+		panic(fmt.Sprintf("solver %q registered twice", name))
+	}
+	solvers[name] = s
+}
+
+// LookupSolver returns the solver registered under name
+func LookupSolver(name string) (Solver, bool) {
+	s, ok := solvers[name]
+	return s, ok
+}
+
+// SolverNames returns the names of every registered solver, sorted
+func SolverNames() []string {
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flowSolver turns a search over flow levels, like edmondsKarp, into a Solver
+func flowSolver(search func(af *AntFarm, ctx context.Context, numAnts int) *pathSet) Solver {
+	return SolverFunc(func(ctx context.Context, af *AntFarm, numAnts int) (*Schedule, error) {
+		best := search(af, ctx, numAnts)
+		if len(best.paths) == 0 {
+			if best.partial {
+				return nil, stopped(ctx)
+			}
+			return nil, newParseError(ErrNoPath, "no path exists between start and end rooms", "", 0)
+		}
+		// A stopped search still needs the moves for the best set it
+		// found; otherwise writing them out stops with ctx as well
+		scheduleCtx := ctx
+		if best.partial {
+			scheduleCtx = context.WithoutCancel(ctx)
+		}
+		ants, turns, err := af.schedule(scheduleCtx, best.paths, numAnts)
+		if err != nil {
+			return nil, err
+		}
+		s := &Schedule{
+			Paths:      best.paths,
+			Ants:       ants,
+			Turns:      turns,
+			LowerBound: lowerBound(best.maxFlow, best.shortest, numAnts),
+			Partial:    best.partial,
+		}
+		if best.partial {
+			// Higher flow levels may exist, so the bound can only count
+			// on the tunnels around the start and end rooms
+			s.LowerBound = lowerBound(af.maxFlowBound(), best.shortest, numAnts)
+			s.Warnings = append(s.Warnings, fmt.Sprintf(
+				"search stopped early (%v); using the best of the %s found so far",
+				ctx.Err(), plural(best.maxFlow, "flow level")))
+		}
+		return s, nil
+	})
+}
+
+// pathSet keeps the best set of paths seen while a solver goes through
+// increasing flow levels
+type pathSet struct {
+	af       *AntFarm
+	numAnts  int
+	paths    [][]string // Best set so far, shortest path first
+	turns    int        // Turns needed with the best set
+	maxFlow  int        // Number of paths at the highest level seen
+	shortest int        // Turns taken by the shortest path
+	partial  bool       // The search was stopped before the last level
+}
+
+func newPathSet(af *AntFarm, numAnts int) *pathSet {
+	return &pathSet{af: af, numAnts: numAnts, paths: make([][]string, 0)}
+}
+
+// add records the path set for one more flow level and keeps it if it
+// moves all the ants in fewer turns. More paths only help if there are
+// enough ants to fill them, so on a tie the larger set is kept
+func (ps *pathSet) add(paths [][]string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return ps.af.pathLength(paths[i]) < ps.af.pathLength(paths[j])
+	})
+	if ps.maxFlow == 0 && len(paths) > 0 {
+		// The first path found is a shortest one, unless tunnels take
+		// different times
+		ps.shortest = len(paths[0]) - 1
+		if g := ps.af.graph(); g.lengths != nil {
+			ps.shortest = g.distances(g.start)[g.end]
+		}
+	}
+	ps.maxFlow = len(paths)
+
+	turns, _ := findOptimalTurns(calculatePathsInfo(ps.af.expandPaths(paths)), ps.numAnts)
+	if len(ps.paths) == 0 || turns <= ps.turns {
+		ps.paths = paths
+		ps.turns = turns
+	}
+}
+
+// stop reports whether the search should end because ctx is done, and
+// marks the set as partial when it is
+func (ps *pathSet) stop(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		ps.partial = true
+	}
+	return ps.partial
+}
+
+// usePathSet stores the result of a search on the farm, for SimulateAnts
+// and LowerBound
+func (af *AntFarm) usePathSet(ps *pathSet) {
+	af.paths, af.maxFlow, af.shortest = ps.paths, ps.maxFlow, ps.shortest
+}
@@ -0,0 +1,268 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSolverRegistry(t *testing.T) {
+	want := []string{"edmonds-karp", "mincost"}
+	for _, name := range want {
+		if _, ok := LookupSolver(name); !ok {
+			t.Errorf("LookupSolver(%q) not found", name)
+		}
+	}
+	if _, ok := LookupSolver("unknown"); ok {
+		t.Error("LookupSolver() found an unregistered solver")
+	}
+	if got := SolverNames(); len(got) < len(want) {
+		t.Errorf("SolverNames() = %v, want at least %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterSolver() did not panic on a duplicate name")
+		}
+	}()
+	RegisterSolver("edmonds-karp", SolverFunc(func(context.Context, *AntFarm, int) (*Schedule, error) { return nil, nil }))
+}
+
+// TestSolversSideBySide runs every registered solver on the same farms
+// and checks each gives a valid schedule that respects the lower bound
+func TestSolversSideBySide(t *testing.T) {
+	farms, err := filepath.Glob("testfarms/regression/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	farms = append(farms, "testfarms/validfarm.txt", "../cmd/farm.txt")
+
+	for _, farmFile := range farms {
+		af := NewAntFarm()
+		if _, err := af.ParseInput(farmFile); err != nil {
+			t.Fatalf("ParseInput(%s) unexpected error: %v", farmFile, err)
+		}
+		before := af.Rooms()
+
+		for _, name := range SolverNames() {
+			t.Run(filepath.Base(farmFile)+"/"+name, func(t *testing.T) {
+				solver, _ := LookupSolver(name)
+				schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+				if err != nil {
+					t.Fatalf("Solve() unexpected error: %v", err)
+				}
+				if err := af.VerifyMoves(schedule.Lines()); err != nil {
+					t.Errorf("Solve() gives an invalid schedule: %v", err)
+				}
+				if len(schedule.Turns) < schedule.LowerBound {
+					t.Errorf("Solve() takes %d turns, below the lower bound %d", len(schedule.Turns), schedule.LowerBound)
+				}
+				sent := 0
+				for _, n := range schedule.Ants {
+					sent += n
+				}
+				if sent != af.numAnts || len(schedule.Ants) != len(schedule.Paths) {
+					t.Errorf("Solve() sends %d ants over %d paths, want %d ants over %d paths",
+						sent, len(schedule.Ants), af.numAnts, len(schedule.Paths))
+				}
+				if af.paths != nil || !reflect.DeepEqual(af.Rooms(), before) {
+					t.Error("Solve() changed the farm")
+				}
+			})
+		}
+	}
+}
+
+func TestSolverNoPath(t *testing.T) {
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}})
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		if _, err := solver.Solve(context.Background(), af, 1); err == nil {
+			t.Errorf("%s: Solve() expected error when there is no path", name)
+		}
+	}
+}
+
+// stopAfter is a context whose Err starts reporting it canceled after n
+// calls, to stop a search at a known point
+type stopAfter struct {
+	context.Context
+	n int
+}
+
+func (c *stopAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestSolverCanceled(t *testing.T) {
+	af := buildFarm("start", "end", minCostLinks)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		if _, err := solver.Solve(ctx, af, 20); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: Solve() with a canceled context error = %v, want context.Canceled", name, err)
+		}
+	}
+}
+
+func TestSolverPartial(t *testing.T) {
+	af := buildFarm("start", "end", minCostLinks)
+	af.numAnts = 20
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		full, err := solver.Solve(context.Background(), af, af.numAnts)
+		if err != nil {
+			t.Fatalf("%s: Solve() unexpected error: %v", name, err)
+		}
+		// Count how often the whole search checks the context, then stop
+		// it at every point along the way
+		counter := &stopAfter{Context: context.Background(), n: 1 << 30}
+		solver.Solve(counter, af, af.numAnts)
+		checks := 1<<30 - counter.n
+
+		partial := 0
+		for n := 0; n < checks; n++ {
+			schedule, err := solver.Solve(&stopAfter{Context: context.Background(), n: n}, af, af.numAnts)
+			if err != nil {
+				// Stopped before any paths were found or while writing out
+				// the moves
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("%s: Solve() stopped after %d of %d checks, error = %v, want context.Canceled", name, n, checks, err)
+				}
+				continue
+			}
+			partial++
+			if !schedule.Partial || len(schedule.Warnings) == 0 {
+				t.Errorf("%s: Solve() stopped early = partial %v with warnings %v, want partial with a warning",
+					name, schedule.Partial, schedule.Warnings)
+			}
+			if err := af.VerifyMoves(schedule.Lines()); err != nil {
+				t.Errorf("%s: Solve() stopped early gives an invalid schedule: %v", name, err)
+			}
+			if len(schedule.Turns) < len(full.Turns) || schedule.LowerBound > len(full.Turns) {
+				t.Errorf("%s: Solve() stopped early takes %d turns with lower bound %d, full search %d turns",
+					name, len(schedule.Turns), schedule.LowerBound, len(full.Turns))
+			}
+		}
+		if partial == 0 {
+			t.Errorf("%s: Solve() stopped at any of %d checks never gives a partial schedule", name, checks)
+		}
+	}
+}
+
+func TestSolverLengths(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   [][2]string
+		lengths map[[2]string]int
+	}{
+		{
+			name:    "Fewer tunnels take longer",
+			links:   [][2]string{{"start", "a"}, {"a", "end"}, {"start", "b"}, {"b", "c"}, {"c", "d"}, {"d", "end"}},
+			lengths: map[[2]string]int{linkKey("start", "a"): 5},
+		},
+		{
+			// Every path goes through m, so only the way there matters
+			name:    "Long way round to a cut room",
+			links:   [][2]string{{"start", "m"}, {"m", "end"}, {"start", "p"}, {"p", "q"}, {"q", "m"}},
+			lengths: map[[2]string]int{linkKey("start", "m"): 10},
+		},
+	}
+	exact, _ := LookupSolver("exact")
+
+	for _, tt := range tests {
+		af := buildFarm("start", "end", tt.links)
+		af.lengths = tt.lengths
+		for _, numAnts := range []int{1, 2, 3, 6} {
+			af.numAnts = numAnts
+			want, err := exact.Solve(context.Background(), af, numAnts)
+			if err != nil {
+				t.Fatalf("%s: exact: Solve() unexpected error: %v", tt.name, err)
+			}
+			for _, name := range SolverNames() {
+				solver, _ := LookupSolver(name)
+				schedule, err := solver.Solve(context.Background(), af, numAnts)
+				if err != nil {
+					t.Fatalf("%s: %s: Solve() unexpected error: %v", tt.name, name, err)
+				}
+				if err := af.VerifyMoves(schedule.Lines()); err != nil {
+					t.Errorf("%s: %s: Solve() with %d ants gives an invalid schedule: %v", tt.name, name, numAnts, err)
+				}
+				if len(schedule.Turns) != len(want.Turns) {
+					t.Errorf("%s: %s: Solve() with %d ants takes %d turns, want %d",
+						tt.name, name, numAnts, len(schedule.Turns), len(want.Turns))
+				}
+			}
+		}
+	}
+}
+
+func TestSolversConcurrent(t *testing.T) {
+	af := NewAntFarm()
+	if _, err := af.ParseInput("../cmd/farm.txt"); err != nil {
+		t.Fatalf("ParseInput() unexpected error: %v", err)
+	}
+	af.SetSeed(42)
+
+	want := make(map[string][]string)
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+		if err != nil {
+			t.Fatalf("%s: Solve() unexpected error: %v", name, err)
+		}
+		want[name] = schedule.Lines()
+	}
+
+	// Seeded solvers running at once each get the moves they get alone
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, name := range SolverNames() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				solver, _ := LookupSolver(name)
+				schedule, err := solver.Solve(context.Background(), af, af.numAnts)
+				if err != nil {
+					t.Errorf("%s: Solve() unexpected error: %v", name, err)
+					return
+				}
+				if !reflect.DeepEqual(schedule.Lines(), want[name]) {
+					t.Errorf("%s: Solve() alongside other solvers = %v, want %v", name, schedule.Lines(), want[name])
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestSolverCanceledWritingMoves(t *testing.T) {
+	// Paths are found at once, but the moves take a turn for every ant
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "end"}})
+	const numAnts = 1000
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		counter := &stopAfter{Context: context.Background(), n: 1 << 30}
+		if _, err := solver.Solve(counter, af, numAnts); err != nil {
+			t.Fatalf("%s: Solve() unexpected error: %v", name, err)
+		}
+		checks := 1<<30 - counter.n
+		if checks < numAnts {
+			t.Fatalf("%s: Solve() checked the context %d times, want at least once a turn", name, checks)
+		}
+
+		// Stopping with most of the moves still to write gives no schedule,
+		// since a truncated one would leave ants behind
+		schedule, err := solver.Solve(&stopAfter{Context: context.Background(), n: checks - numAnts/2}, af, numAnts)
+		if !errors.Is(err, context.Canceled) || schedule != nil {
+			t.Errorf("%s: Solve() stopped while writing moves = %v, %v, want context.Canceled", name, schedule, err)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package internal
 
 import (
-	"fmt"
-	"os"
+	"math/rand"
+	"sync"
 )
 
 type Room struct {
@@ -20,6 +20,15 @@ type AntFarm struct {
 	endRoom   *Room
 	numAnts   int
 	paths     [][]string
+	maxFlow   int                    // Number of paths in the maximum flow found by EdmondsKarp
+	shortest  int                    // Length of the shortest path found by EdmondsKarp
+	seed      *int64                 // Seed for random tie-breaking in path search, nil for a fixed order
+	order     []string               // Room names in the order they were parsed
+	links     [][2]string            // Links in the order they were parsed
+	linked    map[[2]string]struct{} // Every link in links by linkKey, to find duplicates
+	lengths   map[[2]string]int      // Turns taken by tunnels longer than one, by linkKey
+	numbered  *graph                 // Built by graph, nil until needed or after a change
+	graphMu   sync.Mutex
 }
 type PathValidation struct {
 	visited map[string]bool
@@ -28,8 +37,8 @@ type PathValidation struct {
 // PathInfo holds information about each path, including the path itself,
 // its length, and the capacity of ants that can use it.
 type PathInfo struct {
-	path     []string // List of rooms in the path
-	length   int      // Number of rooms in the path (excluding start and end)
+	path     []string // List of rooms in the path, "" for each extra turn spent inside a tunnel
+	length   int      // Number of turns it takes to walk the path
 	capacity int      // Number of ants that can currently be assigned to this path
 }
 
@@ -40,90 +49,95 @@ func NewAntFarm() *AntFarm {
 	}
 }
 
-func Reading(file string) {
-	if file == example {
-		fmt.Println(result)
-		os.Exit(0)
+// Name returns the room's name
+func (r *Room) Name() string {
+	return r.name
+}
+
+// Coordinates returns the room's x and y position
+func (r *Room) Coordinates() (int, int) {
+	return r.x, r.y
+}
+
+// Connections returns the rooms linked to this one
+func (r *Room) Connections() []*Room {
+	return r.connections
+}
+
+// Room looks up a room by name
+func (af *AntFarm) Room(name string) (*Room, bool) {
+	room, ok := af.rooms[name]
+	return room, ok
+}
+
+// Rooms returns every room in the order they were parsed
+func (af *AntFarm) Rooms() []*Room {
+	rooms := make([]*Room, 0, len(af.order))
+	for _, name := range af.order {
+		rooms = append(rooms, af.rooms[name])
+	}
+	return rooms
+}
+
+// Links returns every link as a pair of room names, in the order they were parsed
+func (af *AntFarm) Links() [][2]string {
+	return af.links
+}
+
+// LinkLength returns the number of turns ants take to go through the
+// tunnel between two rooms, 1 unless the link gave a length
+func (af *AntFarm) LinkLength(a, b string) int {
+	if n, ok := af.lengths[linkKey(a, b)]; ok {
+		return n
+	}
+	return 1
+}
 
+// pathLength returns the number of turns an ant takes to walk a path
+// without waiting
+func (af *AntFarm) pathLength(path []string) int {
+	if len(af.lengths) == 0 {
+		return len(path) - 1
+	}
+	length := 0
+	for i := 1; i < len(path); i++ {
+		length += af.LinkLength(path[i-1], path[i])
 	}
+	return length
+}
+
+// StartRoom returns the room marked with ##start
+func (af *AntFarm) StartRoom() *Room {
+	return af.startRoom
+}
+
+// EndRoom returns the room marked with ##end
+func (af *AntFarm) EndRoom() *Room {
+	return af.endRoom
+}
+
+// NumAnts returns the number of ants to move
+func (af *AntFarm) NumAnts() int {
+	return af.numAnts
+}
+
+// Paths returns the paths found by the last call to EdmondsKarp
+func (af *AntFarm) Paths() [][]string {
+	return af.paths
 }
 
-var example = `10
-##start
-start 1 6
-0 4 8
-o 6 8
-n 6 6
-e 8 4
-t 1 9
-E 5 9
-a 8 9
-m 8 6
-h 4 6
-A 5 2
-c 8 1
-k 11 2
-##end
-end 11 6
-start-t
-n-e
-a-m
-A-c
-0-o
-E-a
-k-end
-start-h
-o-n
-m-end
-t-E
-start-0
-h-A
-e-end
-c-k
-n-m
-h-n
-`
-
-var result = `10
-##start
-start 1 6
-0 4 8
-o 6 8
-n 6 6
-e 8 4
-t 1 9
-E 5 9
-a 8 9
-m 8 6
-h 4 6
-A 5 2
-c 8 1
-k 11 2
-##end
-end 11 6
-start-t
-n-e
-a-m
-A-c
-0-o
-E-a
-k-end
-start-h
-o-n
-m-end
-t-E
-start-0
-h-A
-e-end
-c-k
-n-m
-h-n
-
-L1-h L2-t L3-0
-L1-n L2-E L3-o L4-h L5-t
-L1-e L2-a L3-n L5-E L6-h L7-t
-L1-end L2-m L3-h L4-n L5-a L7-E L8-t
-L2-end L3-A L4-e L5-m L6-n L7-a L8-E L9-h
-L10-h L3-c L4-end L5-end L6-e L7-m L8-a L9-n
-L10-n L3-k L6-end L7-end L8-m L9-e
-L10-end L3-end L8-end L9-end`
+// SetSeed makes path search break ties between equally short paths
+// randomly, using the given seed so a run can still be reproduced
+func (af *AntFarm) SetSeed(seed int64) {
+	af.seed = &seed
+}
+
+// newRand gives each search its own source of random tie-breaking, since
+// a rand.Rand can't be shared by solvers running at the same time. It is
+// nil when no seed is set
+func (af *AntFarm) newRand() *rand.Rand {
+	if af.seed == nil {
+		return nil
+	}
+	return rand.New(rand.NewSource(*af.seed))
+}
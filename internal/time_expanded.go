@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxExpandedEdges is the largest time-expanded network the exact
+// solver builds before falling back to the heuristic
+const DefaultMaxExpandedEdges = 2_000_000
+
+func init() {
+	RegisterSolver("exact", &ExactSolver{MaxEdges: DefaultMaxExpandedEdges})
+}
+
+// ExactSolver finds a schedule with the provably smallest number of turns.
+// It builds a time-expanded network, with a copy of every room for every
+// turn, and looks for the fewest turns whose network can carry all the
+// ants. Paths are not fixed beforehand, so ants can wait or take routes
+// no path set would contain. Farms whose network would have more than
+// MaxEdges edges get the min-cost heuristic instead, with a warning.
+// Stopped early, it returns the fastest schedule found so far and the
+// fewest turns not yet ruled out as the lower bound
+type ExactSolver struct {
+	MaxEdges int
+}
+
+// Solve implements Solver
+func (s *ExactSolver) Solve(ctx context.Context, af *AntFarm, numAnts int) (*Schedule, error) {
+	heuristic, _ := LookupSolver("mincost")
+	best, err := heuristic.Solve(ctx, af, numAnts)
+	if err != nil || best.Partial {
+		return best, err
+	}
+
+	// The heuristic gives an upper bound, so only fewer turns need checking
+	low, high := best.LowerBound, len(best.Turns)-1
+	if low > high {
+		return best, nil
+	}
+	// stoppedAt marks the fastest schedule found as partial, with the
+	// fewest turns not yet ruled out as its lower bound
+	stoppedAt := func(found *Schedule, low int) *Schedule {
+		found.LowerBound = low
+		found.Partial = true
+		found.Warnings = append(found.Warnings, fmt.Sprintf(
+			"exact search stopped early (%v); the best schedule found takes %d turns, fewer than %d is impossible",
+			ctx.Err(), len(found.Turns), low))
+		return found
+	}
+
+	te := newTimeExpanded(af)
+	edges, err := te.countEdges(ctx, high, s.MaxEdges)
+	if err != nil {
+		return stoppedAt(best, low), nil
+	}
+	if edges > s.MaxEdges {
+		best.Warnings = append(best.Warnings, fmt.Sprintf(
+			"exact solver skipped: time-expanded network needs more than %d edges; using the mincost heuristic",
+			s.MaxEdges))
+		return best, nil
+	}
+
+	// Binary search for the fewest turns that can move every ant
+	found := best
+	for low <= high {
+		turns := (low + high) / 2
+		schedule, err := te.solve(ctx, turns, numAnts)
+		if err != nil {
+			return stoppedAt(found, low), nil
+		}
+		if schedule != nil {
+			found = schedule
+			high = turns - 1
+		} else {
+			low = turns + 1
+		}
+	}
+	// Nothing faster than found exists, which may be the heuristic's
+	found.LowerBound = len(found.Turns)
+	return found, nil
+}
+
+// timeExpanded builds flow networks where node (room, t) is the room at
+// the end of turn t
+type timeExpanded struct {
+	g         *graph
+	links     [][2]int // Every tunnel once, as room numbers
+	lengths   []int    // Turns taken by each of links
+	fromStart []int    // Shortest distance from the start room, -1 if unreachable
+	toEnd     []int    // Shortest distance to the end room, -1 if unreachable
+}
+
+func newTimeExpanded(af *AntFarm) *timeExpanded {
+	g := af.graph()
+	te := &timeExpanded{g: g}
+	for i, links := range g.links {
+		for k, j := range links {
+			if i < j {
+				te.links = append(te.links, [2]int{i, j})
+				te.lengths = append(te.lengths, g.length(i, k))
+			}
+		}
+	}
+	te.fromStart = g.distances(g.start)
+	te.toEnd = g.distances(g.end)
+	return te
+}
+
+// useful reports whether an ant can be in room i at the end of turn t and
+// still reach the end room by the last turn
+func (te *timeExpanded) useful(i, t, turns int) bool {
+	ds, de := te.fromStart[i], te.toEnd[i]
+	return ds >= 0 && de >= 0 && ds <= t && de <= turns-t
+}
+
+// countEdges returns how many edges the network for the given number of
+// turns has, without building it. It stops counting once there are more
+// than limit, and returns the error from stopped when ctx is done first
+func (te *timeExpanded) countEdges(ctx context.Context, turns, limit int) (int, error) {
+	edges := 0
+	for t := 0; t <= turns && edges <= limit; t++ {
+		if ctx.Err() != nil {
+			return 0, stopped(ctx)
+		}
+		edges += 5 * len(te.links) // Two ways in, capacity, two ways out
+		for i := range te.g.names {
+			if te.useful(i, t, turns) {
+				edges += 2 // Room capacity and waiting
+			}
+		}
+	}
+	return edges, nil
+}
+
+// solve returns a schedule moving numAnts ants in the given number of
+// turns, or nil when that is impossible. It returns the error from stopped
+// when ctx is done before it can tell
+func (te *timeExpanded) solve(ctx context.Context, turns, numAnts int) (*Schedule, error) {
+	if ctx.Err() != nil {
+		return nil, stopped(ctx)
+	}
+	rooms := te.g.names
+	g := newFlowNetwork()
+	type place struct {
+		room int // Room number, -1 for tunnels
+		t    int
+	}
+	var places []place
+	addNode := func(room, t int) int {
+		places = append(places, place{room, t})
+		return g.addNode()
+	}
+
+	// in and out nodes for each room and turn, -1 when the ant couldn't
+	// make it there or to the end in time. The start and end rooms hold
+	// any number of ants so they only get one node
+	in := make([][]int, turns+1)
+	out := make([][]int, turns+1)
+	for t := 0; t <= turns; t++ {
+		in[t], out[t] = make([]int, len(rooms)), make([]int, len(rooms))
+		for i := range rooms {
+			in[t][i], out[t][i] = -1, -1
+			if !te.useful(i, t, turns) {
+				continue
+			}
+			in[t][i] = addNode(i, t)
+			out[t][i] = in[t][i]
+			if !te.g.terminal(i) {
+				out[t][i] = addNode(i, t)
+				g.addEdge(in[t][i], out[t][i], 1)
+			}
+		}
+	}
+
+	startIdx, endIdx := te.g.start, te.g.end
+	if in[0][startIdx] < 0 {
+		return nil, nil
+	}
+	source := addNode(-1, 0)
+	g.addEdge(source, in[0][startIdx], numAnts)
+
+	for t := 0; t < turns; t++ {
+		// Waiting in a room
+		for i := range rooms {
+			if out[t][i] >= 0 && in[t+1][i] >= 0 {
+				c := 1
+				if te.g.terminal(i) {
+					c = numAnts
+				}
+				g.addEdge(out[t][i], in[t+1][i], c)
+			}
+		}
+		// One ant a turn goes into each tunnel, whichever way, and comes
+		// out as many turns later as the tunnel takes. Nobody leaves the
+		// end room or goes back into the start room
+		for l, link := range te.links {
+			arrive := t + te.lengths[l]
+			if arrive > turns {
+				continue
+			}
+			tunnelIn, tunnelOut := -1, -1
+			for k, from := range link {
+				to := link[1-k]
+				if out[t][from] < 0 || in[arrive][to] < 0 || from == endIdx || to == startIdx {
+					continue
+				}
+				if tunnelIn < 0 {
+					tunnelIn, tunnelOut = addNode(-1, t), addNode(-1, t)
+					g.addEdge(tunnelIn, tunnelOut, 1)
+				}
+				g.addEdge(out[t][from], tunnelIn, 1)
+				g.addEdge(tunnelOut, in[arrive][to], 1)
+			}
+		}
+	}
+
+	sink := in[turns][endIdx]
+	if sink < 0 {
+		return nil, nil
+	}
+	if flow := g.maxFlow(ctx, source, sink); flow < numAnts {
+		if ctx.Err() != nil {
+			return nil, stopped(ctx)
+		}
+		return nil, nil
+	}
+
+	// Follow one unit of flow per ant to find where it is after each turn
+	routes := make([][]int, 0, numAnts)
+	for ant := 0; ant < numAnts; ant++ {
+		route := make([]int, turns+1)
+		for t := range route {
+			route[t] = startIdx
+		}
+		for node := in[0][startIdx]; node != sink; {
+			e := g.takeFlow(node)
+			node = g.to[e]
+			if p := places[node]; p.room >= 0 {
+				for t := p.t; t <= turns; t++ {
+					route[t] = p.room
+				}
+			}
+		}
+		routes = append(routes, route)
+	}
+	return te.schedule(routes, turns), nil
+}
+
+// schedule numbers the ants by when they leave the start room and turns
+// their routes into moves and paths
+func (te *timeExpanded) schedule(routes [][]int, turns int) *Schedule {
+	departure := func(route []int) int {
+		for t, room := range route {
+			if room != route[0] {
+				return t
+			}
+		}
+		return len(route)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return departure(routes[i]) < departure(routes[j])
+	})
+
+	schedule := &Schedule{Paths: make([][]string, 0)}
+	pathIndex := make(map[string]int)
+	for _, route := range routes {
+		path := []string{te.g.names[route[0]]}
+		for t := 1; t < len(route); t++ {
+			if route[t] != route[t-1] {
+				path = append(path, te.g.names[route[t]])
+			}
+		}
+		key := strings.Join(path, " ")
+		i, seen := pathIndex[key]
+		if !seen {
+			i = len(schedule.Paths)
+			pathIndex[key] = i
+			schedule.Paths = append(schedule.Paths, path)
+			schedule.Ants = append(schedule.Ants, 0)
+		}
+		schedule.Ants[i]++
+	}
+
+	for t := 1; t <= turns; t++ {
+		// Turns with every ant on the way inside a long tunnel are empty
+		moves := make([]Move, 0)
+		for ant, route := range routes {
+			if route[t] != route[t-1] {
+				moves = append(moves, Move{Ant: ant + 1, Room: te.g.names[route[t]]})
+			}
+		}
+		schedule.Turns = append(schedule.Turns, moves)
+	}
+	return schedule
+}
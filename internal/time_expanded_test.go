@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExactSolver(t *testing.T) {
+	heuristics := []string{"edmonds-karp", "mincost"}
+	for _, numAnts := range []int{1, 2, 3, 5, 8, 20} {
+		af := buildFarm("start", "end", minCostLinks)
+		af.numAnts = numAnts
+
+		exact, err := (&ExactSolver{MaxEdges: DefaultMaxExpandedEdges}).Solve(context.Background(), af, numAnts)
+		if err != nil {
+			t.Fatalf("%d ants: Solve() unexpected error: %v", numAnts, err)
+		}
+		if err := af.VerifyMoves(exact.Lines()); err != nil {
+			t.Errorf("%d ants: Solve() gives an invalid schedule: %v", numAnts, err)
+		}
+		if len(exact.Turns) != exact.LowerBound || len(exact.Warnings) != 0 {
+			t.Errorf("%d ants: Solve() = %d turns, lower bound %d, warnings %v, want a proven optimum",
+				numAnts, len(exact.Turns), exact.LowerBound, exact.Warnings)
+		}
+		for _, name := range heuristics {
+			solver, _ := LookupSolver(name)
+			other, _ := solver.Solve(context.Background(), af, numAnts)
+			if len(exact.Turns) > len(other.Turns) {
+				t.Errorf("%d ants: exact takes %d turns, %s only %d", numAnts, len(exact.Turns), name, len(other.Turns))
+			}
+		}
+	}
+}
+
+func TestExactSolverWaiting(t *testing.T) {
+	// A single path of length 2 with 3 ants can't be done in fewer than
+	// 4 turns, and every ant has to wait for the one ahead
+	af := buildFarm("start", "end", [][2]string{{"start", "a"}, {"a", "end"}})
+	af.numAnts = 3
+	te := newTimeExpanded(af)
+	if got, err := te.solve(context.Background(), 3, 3); got != nil || err != nil {
+		t.Errorf("solve() in 3 turns = %v, %v, want impossible", got, err)
+	}
+	got, _ := te.solve(context.Background(), 4, 3)
+	want := []string{"L1-a", "L1-end L2-a", "L2-end L3-a", "L3-end"}
+	if got == nil || strings.Join(got.Lines(), "|") != strings.Join(want, "|") {
+		t.Errorf("solve() in 4 turns = %v, want %v", got, want)
+	}
+}
+
+func TestExactSolverSizeGuard(t *testing.T) {
+	af := buildFarm("start", "end", minCostLinks)
+	af.numAnts = 20
+
+	schedule, err := (&ExactSolver{MaxEdges: 10}).Solve(context.Background(), af, 20)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	if len(schedule.Warnings) != 1 || !strings.Contains(schedule.Warnings[0], "mincost") {
+		t.Errorf("Solve() warnings = %v, want a fallback warning", schedule.Warnings)
+	}
+	if err := af.VerifyMoves(schedule.Lines()); err != nil {
+		t.Errorf("Solve() fallback gives an invalid schedule: %v", err)
+	}
+
+	// Counting stops at the limit however many turns there are
+	te := newTimeExpanded(af)
+	perTurn := 5*len(te.links) + 2*len(te.g.names)
+	edges, err := te.countEdges(context.Background(), math.MaxInt32, 10)
+	if err != nil || edges <= 10 || edges > 10+perTurn {
+		t.Errorf("countEdges() = %d, %v, want just over 10", edges, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := te.countEdges(ctx, math.MaxInt32, math.MaxInt); !errors.Is(err, context.Canceled) {
+		t.Errorf("countEdges() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
@@ -18,22 +18,26 @@ func (af *AntFarm) ValidateStartEndPath() error {
 	return nil
 }
 
-// hasPath performs DFS to check if there's a path between start and end rooms
-func (pv *PathValidation) hasPath(current *Room, end *Room) bool {
-	// If we've reached the end room, we found a path
-	if current == end {
-		return true
-	}
+// hasPath performs DFS to check if there's a path between start and end
+// rooms. It keeps its own stack so long chains of rooms can't overflow
+func (pv *PathValidation) hasPath(start *Room, end *Room) bool {
+	stack := []*Room{start}
+	pv.visited[start.name] = true
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
 
-	// Mark current room as visited
-	pv.visited[current.name] = true
+		// If we've reached the end room, we found a path
+		if current == end {
+			return true
+		}
 
-	// Check all connections from current room
-	for _, nextRoom := range current.connections {
-		// If room hasn't been visited, explore it
-		if !pv.visited[nextRoom.name] {
-			if pv.hasPath(nextRoom, end) {
-				return true
+		// Check all connections from current room
+		for _, nextRoom := range current.connections {
+			if !pv.visited[nextRoom.name] {
+				pv.visited[nextRoom.name] = true
+				stack = append(stack, nextRoom)
 			}
 		}
 	}
@@ -0,0 +1,154 @@
+package internal
+
+import "fmt"
+
+// VerifyError describes the first rule a list of moves breaks
+type VerifyError struct {
+	Turn   int    // 1-based turn the rule is broken on
+	Ant    int    // Ant breaking the rule
+	Reason string // What went wrong
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("turn %d, ant %d: %s", e.Turn, e.Ant, e.Reason)
+}
+
+// VerifyMoves checks that the lines of Lx-room moves, one line per turn,
+// are a valid solution for the farm: ants only follow links, move at most
+// once per turn, never share an intermediate room or go into a tunnel in
+// the same turn, and all end up in the end room. A move is written on the
+// turn the ant arrives, so going through a tunnel that takes n turns it
+// leaves n-1 turns earlier and its room is free from then on. The first
+// violation is returned
+func (af *AntFarm) VerifyMoves(lines []string) error {
+	turns := make([][]Move, len(lines))
+	parseErrs := make([]error, len(lines))
+	// Each ant's moves in order, to tell when it leaves the room it is in
+	type arrival struct {
+		turn int
+		room string
+	}
+	arrivals := make(map[int][]arrival, af.numAnts)
+	for i, line := range lines {
+		turns[i], parseErrs[i] = ParseMoves(line)
+		for _, m := range turns[i] {
+			arrivals[m.Ant] = append(arrivals[m.Ant], arrival{i + 1, m.Room})
+		}
+	}
+
+	position := make(map[int]*Room, af.numAnts)
+	arrived := make(map[int]int, af.numAnts) // Turn each ant got to its room
+	count := make(map[int]int, af.numAnts)   // Moves each ant has made
+	for ant := 1; ant <= af.numAnts; ant++ {
+		position[ant] = af.startRoom
+	}
+	// leaves returns the turn the ant goes into its next tunnel, or 0 when
+	// it stays where it is. It can't be before the ant got there, even if
+	// the next move comes too soon
+	leaves := func(ant int) int {
+		if count[ant] >= len(arrivals[ant]) {
+			return 0
+		}
+		next := arrivals[ant][count[ant]]
+		return max(next.turn-af.LinkLength(position[ant].name, next.room)+1, arrived[ant]+1)
+	}
+
+	type entry struct {
+		tunnel [2]string
+		turn   int
+	}
+	entered := make(map[entry]bool)
+	for i, moves := range turns {
+		turn := i + 1
+		if parseErrs[i] != nil {
+			return &VerifyError{Turn: turn, Reason: parseErrs[i].Error()}
+		}
+
+		moved := make(map[int]bool)
+		for _, m := range moves {
+			fail := func(format string, args ...interface{}) error {
+				return &VerifyError{Turn: turn, Ant: m.Ant, Reason: fmt.Sprintf(format, args...)}
+			}
+
+			from, ok := position[m.Ant]
+			if !ok {
+				return fail("there are only %d ants", af.numAnts)
+			}
+			if moved[m.Ant] {
+				return fail("moves more than once")
+			}
+			moved[m.Ant] = true
+			if from == af.endRoom {
+				return fail("moves after reaching the end room")
+			}
+
+			to, ok := af.rooms[m.Room]
+			if !ok {
+				return fail("moves to unknown room %s", m.Room)
+			}
+			if !isLinked(from, to) {
+				return fail("moves from %s to %s without a tunnel", from.name, to.name)
+			}
+			length := af.LinkLength(from.name, to.name)
+			if turn-arrived[m.Ant] < length {
+				return fail("gets from %s to %s in %d turns, the tunnel takes %d",
+					from.name, to.name, turn-arrived[m.Ant], length)
+			}
+
+			tunnel := linkKey(from.name, to.name)
+			e := entry{tunnel, turn - length + 1}
+			if entered[e] && length == 1 {
+				return fail("uses tunnel %s-%s already used this turn", tunnel[0], tunnel[1])
+			}
+			if entered[e] {
+				return fail("goes into tunnel %s-%s on turn %d with another ant", tunnel[0], tunnel[1], e.turn)
+			}
+			entered[e] = true
+			position[m.Ant] = to
+			arrived[m.Ant] = turn
+			count[m.Ant]++
+		}
+
+		// Only the start and end rooms can hold more than one ant. Ants
+		// that have gone into a tunnel are no longer in their room
+		occupant := make(map[*Room]int)
+		for ant := 1; ant <= af.numAnts; ant++ {
+			room := position[ant]
+			if room == af.startRoom || room == af.endRoom {
+				continue
+			}
+			if left := leaves(ant); left != 0 && left <= turn {
+				continue
+			}
+			if other, ok := occupant[room]; ok {
+				return &VerifyError{
+					Turn:   turn,
+					Ant:    ant,
+					Reason: fmt.Sprintf("shares room %s with ant %d", room.name, other),
+				}
+			}
+			occupant[room] = ant
+		}
+	}
+
+	for ant := 1; ant <= af.numAnts; ant++ {
+		if position[ant] != af.endRoom {
+			return &VerifyError{
+				Turn:   len(lines),
+				Ant:    ant,
+				Reason: fmt.Sprintf("never reaches the end room, stopped in %s", position[ant].name),
+			}
+		}
+	}
+	return nil
+}
+
+// isLinked reports whether there is a tunnel from one room to the other
+func isLinked(from, to *Room) bool {
+	for _, conn := range from.connections {
+		if conn == to {
+			return true
+		}
+	}
+	return false
+}
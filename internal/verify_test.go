@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAntFarm_VerifyMoves(t *testing.T) {
+	// start - a - end and start - b - end, plus a-b
+	links := [][2]string{{"start", "a"}, {"a", "end"}, {"start", "b"}, {"b", "end"}, {"a", "b"}}
+
+	tests := []struct {
+		name     string
+		numAnts  int
+		lines    []string
+		wantTurn int
+		wantAnt  int
+	}{
+		{
+			name:    "Valid solution",
+			numAnts: 3,
+			lines:   []string{"L1-a L2-b", "L1-end L2-end L3-a", "L3-end"},
+		},
+		{
+			name:     "Move without a tunnel",
+			numAnts:  1,
+			lines:    []string{"L1-end"},
+			wantTurn: 1,
+			wantAnt:  1,
+		},
+		{
+			name:     "Two ants in one room",
+			numAnts:  2,
+			lines:    []string{"L1-a", "L2-a"},
+			wantTurn: 2,
+			wantAnt:  2,
+		},
+		{
+			name:     "Ant moves twice in a turn",
+			numAnts:  1,
+			lines:    []string{"L1-a L1-end"},
+			wantTurn: 1,
+			wantAnt:  1,
+		},
+		{
+			name:     "Tunnel used twice in a turn",
+			numAnts:  2,
+			lines:    []string{"L1-a L2-b", "L1-b L2-a"},
+			wantTurn: 2,
+			wantAnt:  2,
+		},
+		{
+			name:     "Ant never arrives",
+			numAnts:  2,
+			lines:    []string{"L1-a", "L1-end"},
+			wantTurn: 2,
+			wantAnt:  2,
+		},
+		{
+			name:     "Unknown ant",
+			numAnts:  1,
+			lines:    []string{"L2-a"},
+			wantTurn: 1,
+			wantAnt:  2,
+		},
+		{
+			name:     "Move after arriving",
+			numAnts:  1,
+			lines:    []string{"L1-a", "L1-end", "L1-b"},
+			wantTurn: 3,
+			wantAnt:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := buildFarm("start", "end", links)
+			af.numAnts = tt.numAnts
+			err := af.VerifyMoves(tt.lines)
+
+			if tt.wantTurn == 0 {
+				if err != nil {
+					t.Errorf("VerifyMoves() unexpected error: %v", err)
+				}
+				return
+			}
+			var ve *VerifyError
+			if !errors.As(err, &ve) {
+				t.Fatalf("VerifyMoves() error = %v, want a *VerifyError", err)
+			}
+			if ve.Turn != tt.wantTurn || ve.Ant != tt.wantAnt {
+				t.Errorf("VerifyMoves() = turn %d ant %d (%v), want turn %d ant %d",
+					ve.Turn, ve.Ant, err, tt.wantTurn, tt.wantAnt)
+			}
+		})
+	}
+}
+
+func TestVerifyMovesLengths(t *testing.T) {
+	// start - a - end taking 2 then 3 turns, and start - b - end
+	links := [][2]string{{"start", "a"}, {"a", "end"}, {"start", "b"}, {"b", "end"}}
+
+	tests := []struct {
+		name     string
+		lines    []string
+		wantTurn int
+		wantAnt  int
+	}{
+		{
+			name:  "Valid solution",
+			lines: []string{"L2-b", "L1-a L2-end", "", "", "L1-end"},
+		},
+		{
+			// Ant 1 leaves a for the end as ant 2 gets there
+			name:  "Room left through a long tunnel",
+			lines: []string{"", "L1-a", "L2-a", "", "L1-end", "L2-end"},
+		},
+		{
+			name:     "Arrives too soon",
+			lines:    []string{"L1-a"},
+			wantTurn: 1,
+			wantAnt:  1,
+		},
+		{
+			name:     "Two ants into a tunnel in one turn",
+			lines:    []string{"", "L1-a L2-a"},
+			wantTurn: 2,
+			wantAnt:  2,
+		},
+		{
+			name:     "Room still taken",
+			lines:    []string{"", "L1-a", "L2-a", "", "", "L1-end"},
+			wantTurn: 3,
+			wantAnt:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := buildFarm("start", "end", links)
+			af.lengths = map[[2]string]int{linkKey("start", "a"): 2, linkKey("a", "end"): 3}
+			af.numAnts = 2
+			err := af.VerifyMoves(tt.lines)
+
+			if tt.wantTurn == 0 {
+				if err != nil {
+					t.Errorf("VerifyMoves() unexpected error: %v", err)
+				}
+				return
+			}
+			var ve *VerifyError
+			if !errors.As(err, &ve) {
+				t.Fatalf("VerifyMoves() error = %v, want a *VerifyError", err)
+			}
+			if ve.Turn != tt.wantTurn || ve.Ant != tt.wantAnt {
+				t.Errorf("VerifyMoves() = turn %d ant %d (%v), want turn %d ant %d",
+					ve.Turn, ve.Ant, err, tt.wantTurn, tt.wantAnt)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package lemin
+
+import "io"
+
+// WriteHTML writes a self-contained HTML page animating the solution over
+// the farm's room coordinates, with play/pause and a slider to scrub
+// through the turns
+func WriteHTML(w io.Writer, f *Farm, s *Solution) error {
+	sim, err := f.Simulate(s)
+	if err != nil {
+		return err
+	}
+	return f.af.WriteHTML(w, sim.sim)
+}
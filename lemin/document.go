@@ -0,0 +1,125 @@
+package lemin
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Document is a solution together with the farm it solves, laid out to
+// be written as JSON for tools that shouldn't have to parse Lx-y lines
+type Document struct {
+	Farm       FarmDocument     `json:"farm"`
+	Paths      []PathDocument   `json:"paths"`
+	Turns      int              `json:"turns"`
+	LowerBound int              `json:"lower_bound"`
+	Moves      [][]MoveDocument `json:"moves"` // Moves made during each turn
+	Warnings   []string         `json:"warnings,omitempty"`
+	Partial    bool             `json:"partial,omitempty"` // Solving was stopped early
+}
+
+// FarmDocument describes the farm in a Document
+type FarmDocument struct {
+	Ants  int    `json:"ants"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Rooms []Room `json:"rooms"`
+	Links []Link `json:"links"`
+}
+
+// PathDocument is one path of a Document and the number of ants sent
+// down it
+type PathDocument struct {
+	Rooms []string `json:"rooms"`
+	Ants  int      `json:"ants"`
+}
+
+// MoveDocument is one ant going from one room to another
+type MoveDocument struct {
+	Ant  int    `json:"ant"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NewDocument describes the farm and a solution for it
+func NewDocument(f *Farm, s *Solution) (*Document, error) {
+	doc := &Document{
+		Farm: FarmDocument{
+			Ants:  f.Ants(),
+			Start: f.Start().Name,
+			End:   f.End().Name,
+			Rooms: f.Rooms(),
+			Links: f.Links(),
+		},
+		Paths:      make([]PathDocument, len(s.Paths)),
+		Turns:      len(s.Turns),
+		LowerBound: s.LowerBound,
+		Moves:      make([][]MoveDocument, 0, len(s.Turns)),
+		Warnings:   s.Warnings,
+		Partial:    s.Partial,
+	}
+	for i, path := range s.Paths {
+		doc.Paths[i] = PathDocument{Rooms: path}
+		if i < len(s.Ants) {
+			doc.Paths[i].Ants = s.Ants[i]
+		}
+	}
+
+	// Each ant moves from wherever the previous turn left it
+	sim, err := f.Simulate(s)
+	if err != nil {
+		return nil, err
+	}
+	for !sim.Done() {
+		turn := make([]MoveDocument, 0)
+		from := sim.Ants()
+		for _, m := range sim.Step() {
+			turn = append(turn, MoveDocument{Ant: m.Ant, From: from[m.Ant-1].Room, To: m.Room})
+		}
+		doc.Moves = append(doc.Moves, turn)
+	}
+	return doc, nil
+}
+
+// String writes the farm in the format Parse reads
+func (d FarmDocument) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d\n", d.Ants)
+	for _, room := range d.Rooms {
+		switch room.Name {
+		case d.Start:
+			sb.WriteString("##start\n")
+		case d.End:
+			sb.WriteString("##end\n")
+		}
+		fmt.Fprintf(&sb, "%s %d %d\n", room.Name, room.X, room.Y)
+	}
+	for _, link := range d.Links {
+		fmt.Fprintf(&sb, "%s-%s", link.From, link.To)
+		if link.Length != 0 && link.Length != 1 {
+			fmt.Fprintf(&sb, " %d", link.Length)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ParseDocument builds a farm from its JSON description, checking it
+// the same way Parse does
+func ParseDocument(d FarmDocument) (*Farm, error) {
+	if err := d.checkNames(); err != nil {
+		return nil, err
+	}
+	return Parse(strings.NewReader(d.String()))
+}
+
+// checkNames rejects room names with spaces, which String would write
+// out as something else entirely
+func (d FarmDocument) checkNames() error {
+	for _, room := range d.Rooms {
+		if room.Name == "" || strings.ContainsFunc(room.Name, unicode.IsSpace) {
+			return &ParseError{Text: room.Name, Code: ErrInvalidRoomName, Message: "invalid room name"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package lemin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewDocument(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	solution, err := Solve(farm)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	doc, err := NewDocument(farm, solution)
+	if err != nil {
+		t.Fatalf("NewDocument() unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	var got Document
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+
+	wantFarm := FarmDocument{
+		Ants:  4,
+		Start: "start",
+		End:   "end",
+		Rooms: []Room{{"start", 0, 0}, {"room1", 1, 1}, {"room2", 2, 2}, {"end", 3, 3}},
+		Links: []Link{{"start", "room1", 0}, {"room1", "room2", 0}, {"room2", "end", 0}},
+	}
+	if !reflect.DeepEqual(got.Farm, wantFarm) {
+		t.Errorf("farm = %+v, want %+v", got.Farm, wantFarm)
+	}
+	wantPaths := []PathDocument{{Rooms: []string{"start", "room1", "room2", "end"}, Ants: 4}}
+	if !reflect.DeepEqual(got.Paths, wantPaths) {
+		t.Errorf("paths = %+v, want %+v", got.Paths, wantPaths)
+	}
+	if got.Turns != 6 || len(got.Moves) != 6 || got.LowerBound != 6 {
+		t.Errorf("turns = %d with %d turns of moves, lower bound %d, want 6", got.Turns, len(got.Moves), got.LowerBound)
+	}
+	wantTurn := []MoveDocument{{1, "room1", "room2"}, {2, "start", "room1"}}
+	if !reflect.DeepEqual(got.Moves[1], wantTurn) {
+		t.Errorf("second turn = %+v, want %+v", got.Moves[1], wantTurn)
+	}
+}
@@ -0,0 +1,10 @@
+package lemin
+
+import "io"
+
+// WriteDOT writes the farm as a Graphviz graph with every room at its
+// coordinates and each path of the solution in its own color, labelled
+// with the number of ants sent down it. Render it with neato or fdp
+func WriteDOT(w io.Writer, f *Farm, s *Solution) error {
+	return f.af.WriteDOT(w, s.Paths, s.Ants)
+}
@@ -0,0 +1,28 @@
+package lemin
+
+import "lem-in/internal"
+
+// ParseError describes a problem in a farm description: the line and
+// column it was found at, the offending text and an error code. Use
+// errors.As to get one from the errors returned by Parse and ParseFile.
+type ParseError = internal.ParseError
+
+// ErrorCode identifies the kind of problem in a ParseError
+type ErrorCode = internal.ErrorCode
+
+const (
+	ErrEmptyFile         = internal.ErrEmptyFile
+	ErrInvalidAnts       = internal.ErrInvalidAnts
+	ErrInvalidRoom       = internal.ErrInvalidRoom
+	ErrInvalidRoomName   = internal.ErrInvalidRoomName
+	ErrInvalidCoordinate = internal.ErrInvalidCoordinate
+	ErrMultipleStart     = internal.ErrMultipleStart
+	ErrMultipleEnd       = internal.ErrMultipleEnd
+	ErrInvalidLink       = internal.ErrInvalidLink
+	ErrSelfLink          = internal.ErrSelfLink
+	ErrUnknownRoom       = internal.ErrUnknownRoom
+	ErrDuplicateLink     = internal.ErrDuplicateLink
+	ErrNoStart           = internal.ErrNoStart
+	ErrNoEnd             = internal.ErrNoEnd
+	ErrNoPath            = internal.ErrNoPath
+)
@@ -0,0 +1,108 @@
+// Package lemin parses ant farm descriptions and finds the fewest turns
+// needed to move every ant from the ##start room to the ##end room.
+package lemin
+
+import (
+	"io"
+
+	"lem-in/internal"
+)
+
+// Room is a room of the farm with its coordinates
+type Room struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// Link is a tunnel between two rooms
+type Link struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Length int    `json:"length,omitempty"` // Turns it takes to go through, 0 or 1 for one
+}
+
+// Farm is a parsed and validated ant farm
+type Farm struct {
+	af    *internal.AntFarm
+	input string
+}
+
+// Parse reads and validates the farm description from r
+func Parse(r io.Reader) (*Farm, error) {
+	af := internal.NewAntFarm()
+	input, err := af.ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Farm{af: af, input: input}, nil
+}
+
+// ParseFile reads and validates the farm described in filename
+func ParseFile(filename string) (*Farm, error) {
+	af := internal.NewAntFarm()
+	input, err := af.ParseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Farm{af: af, input: input}, nil
+}
+
+// Diagnose reads the farm description from r and reports every problem
+// in it instead of stopping at the first one, sorted by line
+func Diagnose(r io.Reader) ([]*ParseError, error) {
+	return internal.NewAntFarm().Diagnose(r)
+}
+
+// Input returns the farm description as it was read
+func (f *Farm) Input() string {
+	return f.input
+}
+
+// Ants returns the number of ants to move
+func (f *Farm) Ants() int {
+	return f.af.NumAnts()
+}
+
+// Rooms returns every room in the order they were defined
+func (f *Farm) Rooms() []Room {
+	rooms := make([]Room, 0)
+	for _, r := range f.af.Rooms() {
+		rooms = append(rooms, toRoom(r))
+	}
+	return rooms
+}
+
+// Links returns every tunnel in the order they were defined
+func (f *Farm) Links() []Link {
+	links := make([]Link, 0)
+	for _, l := range f.af.Links() {
+		link := Link{From: l[0], To: l[1]}
+		if n := f.af.LinkLength(l[0], l[1]); n > 1 {
+			link.Length = n
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// Start returns the ##start room
+func (f *Farm) Start() Room {
+	return toRoom(f.af.StartRoom())
+}
+
+// End returns the ##end room
+func (f *Farm) End() Room {
+	return toRoom(f.af.EndRoom())
+}
+
+// SetSeed makes Solve break ties between equally short paths randomly,
+// using the given seed so a run can still be reproduced
+func (f *Farm) SetSeed(seed int64) {
+	f.af.SetSeed(seed)
+}
+
+func toRoom(r *internal.Room) Room {
+	x, y := r.Coordinates()
+	return Room{Name: r.Name(), X: x, Y: y}
+}
@@ -0,0 +1,100 @@
+package lemin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+
+	if got := farm.Ants(); got != 4 {
+		t.Errorf("Ants() = %v, want %v", got, 4)
+	}
+	wantRooms := []Room{
+		{Name: "start", X: 0, Y: 0},
+		{Name: "room1", X: 1, Y: 1},
+		{Name: "room2", X: 2, Y: 2},
+		{Name: "end", X: 3, Y: 3},
+	}
+	if got := farm.Rooms(); !reflect.DeepEqual(got, wantRooms) {
+		t.Errorf("Rooms() = %v, want %v", got, wantRooms)
+	}
+	wantLinks := []Link{
+		{From: "start", To: "room1"},
+		{From: "room1", To: "room2"},
+		{From: "room2", To: "end"},
+	}
+	if got := farm.Links(); !reflect.DeepEqual(got, wantLinks) {
+		t.Errorf("Links() = %v, want %v", got, wantLinks)
+	}
+	if got := farm.Start(); got != wantRooms[0] {
+		t.Errorf("Start() = %v, want %v", got, wantRooms[0])
+	}
+	if got := farm.End(); got != wantRooms[3] {
+		t.Errorf("End() = %v, want %v", got, wantRooms[3])
+	}
+	if !strings.HasPrefix(farm.Input(), "4\n##start\n") {
+		t.Errorf("Input() = %q, want the file content", farm.Input())
+	}
+}
+
+func TestParseFileError(t *testing.T) {
+	if _, err := ParseFile("../internal/testfarms/invalidfarm1.txt"); err == nil {
+		t.Error("ParseFile() expected error for invalid farm")
+	}
+}
+
+func TestParse(t *testing.T) {
+	farm, err := Parse(strings.NewReader("2\n##start\ns 0 0\n##end\ne 1 0\ns-e\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if farm.Start().Name != "s" || farm.End().Name != "e" || farm.Ants() != 2 {
+		t.Errorf("Parse() = start %v, end %v, ants %v", farm.Start(), farm.End(), farm.Ants())
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	problems, err := Diagnose(strings.NewReader("2\n##start\ns 0 0\ns-x\n"))
+	if err != nil {
+		t.Fatalf("Diagnose() unexpected error: %v", err)
+	}
+	if len(problems) != 2 || problems[0].Code != ErrUnknownRoom || problems[1].Code != ErrNoEnd {
+		t.Errorf("Diagnose() = %v, want unknown room then no end room", problems)
+	}
+}
+
+func TestParseLengths(t *testing.T) {
+	farm, err := Parse(strings.NewReader("1\n##start\ns 0 0\na 1 0\n##end\ne 2 0\ns-a 3\na-e\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	want := []Link{{From: "s", To: "a", Length: 3}, {From: "a", To: "e"}}
+	if got := farm.Links(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Links() = %v, want %v", got, want)
+	}
+
+	// The JSON description writes the lengths back out
+	doc := FarmDocument{Ants: 1, Start: "s", End: "e", Rooms: farm.Rooms(), Links: farm.Links()}
+	again, err := ParseDocument(doc)
+	if err != nil {
+		t.Fatalf("ParseDocument() unexpected error: %v", err)
+	}
+	if got := again.Links(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDocument() links = %v, want %v", got, want)
+	}
+
+	s, err := Solve(farm)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	arrival := []Move{{Ant: 1, Room: "a"}}
+	if len(s.Turns) != 4 || len(s.Turns[0]) != 0 || !reflect.DeepEqual(s.Turns[2], arrival) {
+		t.Errorf("Solve() turns = %v, want the ant in a on turn 3 and end on turn 4", s.Turns)
+	}
+}
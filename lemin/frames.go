@@ -0,0 +1,32 @@
+package lemin
+
+import (
+	"io"
+
+	"lem-in/internal"
+)
+
+// FrameOptions sets the size of the frames in pixels and, for a GIF, how
+// long each turn is shown in 100ths of a second
+type FrameOptions = internal.FrameOptions
+
+// WriteGIF writes an animated GIF of the solution with one frame per
+// turn: rooms at their coordinates, tunnels, paths in their colors and
+// every ant on the way as a numbered dot
+func WriteGIF(w io.Writer, f *Farm, s *Solution, opts FrameOptions) error {
+	sim, err := f.Simulate(s)
+	if err != nil {
+		return err
+	}
+	return f.af.WriteGIF(w, sim.sim, opts)
+}
+
+// WritePNGs writes the same frames as WriteGIF as numbered PNG files in
+// dir, frame-000.png being before the first move, and returns their names
+func WritePNGs(dir string, f *Farm, s *Solution, opts FrameOptions) ([]string, error) {
+	sim, err := f.Simulate(s)
+	if err != nil {
+		return nil, err
+	}
+	return f.af.WritePNGs(dir, sim.sim, opts)
+}
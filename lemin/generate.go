@@ -0,0 +1,18 @@
+package lemin
+
+import "lem-in/internal"
+
+// GenerateOptions controls the farm Generate builds: the number of rooms
+// and ants, extra random links per room, the topology and the seed
+type GenerateOptions = internal.GenerateOptions
+
+// Topologies returns the topologies Generate can build
+func Topologies() []string {
+	return append([]string(nil), internal.Topologies...)
+}
+
+// Generate builds a random farm in the format Parse reads. The same
+// options always give the same farm
+func Generate(opts GenerateOptions) (string, error) {
+	return internal.Generate(opts)
+}
@@ -0,0 +1,26 @@
+package lemin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, topology := range Topologies() {
+		input, err := Generate(GenerateOptions{Rooms: 20, Density: 0.5, Ants: 10, Topology: topology, Seed: 1})
+		if err != nil {
+			t.Fatalf("Generate(%s) unexpected error: %v", topology, err)
+		}
+		farm, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Generate(%s) gives a farm Parse rejects: %v", topology, err)
+		}
+		solution, err := Solve(farm)
+		if err != nil {
+			t.Fatalf("Solve() on the %s farm unexpected error: %v", topology, err)
+		}
+		if err := Verify(farm, solution.Lines()); err != nil {
+			t.Errorf("Solve() on the %s farm gives invalid moves: %v", topology, err)
+		}
+	}
+}
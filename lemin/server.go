@@ -0,0 +1,311 @@
+package lemin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Limits a Handler applies when ServerOptions leaves them at zero
+const (
+	DefaultMaxBodyBytes = 10 << 20
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxAnts      = 100_000
+)
+
+// ServerOptions limits the requests a Handler accepts
+type ServerOptions struct {
+	MaxBodyBytes int64         // Largest request body read
+	Timeout      time.Duration // Longest a request may take before it is answered with 503
+	MaxAnts      int           // Most ants in a farm to solve or verify, as a small body can ask for millions
+}
+
+// NewHandler serves the parser, solvers and verifier over HTTP:
+//
+//	POST /solve     farm as text, or as JSON like Document.Farm; ?solver=NAME
+//	                picks the solver, ?timeout=D gives it less time than the
+//	                request and ?partial=true answers with the best found
+//	                when that runs out. Answers with a Document
+//	POST /validate  farm as text or JSON. Answers with every problem found
+//	POST /verify    {"farm": text or JSON, "moves": ["L1-a", ...]}. Answers
+//	                whether the moves are a valid solution
+//	GET  /health    answers {"status": "ok"}
+//
+// Problems with the farm are answered with 422 and the line they are on,
+// as are farms with more than MaxAnts ants to solve or verify
+func NewHandler(opts ServerOptions) http.Handler {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if opts.MaxAnts <= 0 {
+		opts.MaxAnts = DefaultMaxAnts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	s := &server{opts: opts}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("POST /solve", s.limit(s.solve))
+	mux.Handle("POST /validate", s.limit(s.validate))
+	mux.Handle("POST /verify", s.limit(s.verify))
+	return mux
+}
+
+type server struct {
+	opts ServerOptions
+}
+
+// limit caps the size of the request body and the time taken to answer
+func (s *server) limit(h http.HandlerFunc) http.Handler {
+	timeout := http.TimeoutHandler(h, s.opts.Timeout, `{"error":"request timed out"}`)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes)
+		// Set here too, as the answer to a timeout comes without one
+		w.Header().Set("Content-Type", "application/json")
+		timeout.ServeHTTP(w, r)
+	})
+}
+
+// errorResponse is the body of every answer that isn't a success
+type errorResponse struct {
+	Error  string    `json:"error"`
+	Code   ErrorCode `json:"code,omitempty"`
+	Line   int       `json:"line,omitempty"`
+	Column int       `json:"column,omitempty"`
+}
+
+// Problem is one problem with a farm found by /validate
+type Problem struct {
+	Line    int       `json:"line"`
+	Column  int       `json:"column"`
+	Code    ErrorCode `json:"code"`
+	Text    string    `json:"text"`
+	Message string    `json:"message"`
+}
+
+type validateResponse struct {
+	Valid    bool      `json:"valid"`
+	Problems []Problem `json:"problems"`
+}
+
+type verifyRequest struct {
+	Farm  json.RawMessage `json:"farm"` // Farm text as a JSON string, or a FarmDocument
+	Moves []string        `json:"moves"`
+}
+
+type verifyResponse struct {
+	Valid  bool   `json:"valid"`
+	Ants   int    `json:"ants"`
+	Turns  int    `json:"turns"`
+	Error  string `json:"error,omitempty"`
+	Turn   int    `json:"turn,omitempty"` // Turn the first broken rule is on
+	Ant    int    `json:"ant,omitempty"`  // Ant breaking it
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *server) solve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := []Option{WithSolver(DefaultSolver)}
+	if solver := query.Get("solver"); solver != "" {
+		opts = append(opts, WithSolver(solver))
+	}
+	if timeout := query.Get("timeout"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid timeout %q", timeout)})
+			return
+		}
+		opts = append(opts, WithTimeout(d))
+	}
+	if partial := query.Get("partial"); partial != "" {
+		ok, err := strconv.ParseBool(partial)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid partial %q", partial)})
+			return
+		}
+		if ok {
+			opts = append(opts, WithPartial())
+		}
+	}
+	farm, ok := s.readFarm(w, r)
+	if !ok {
+		return
+	}
+	// The request's context ends with its timeout or when the client goes
+	// away, and nobody is waiting for the answer after that
+	solution, err := SolveContext(r.Context(), farm, opts...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	doc, err := NewDocument(farm, solution)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (s *server) validate(w http.ResponseWriter, r *http.Request) {
+	text, ok := s.readFarmText(w, r)
+	if !ok {
+		return
+	}
+	problems, err := Diagnose(bytes.NewReader(text))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := validateResponse{Valid: len(problems) == 0, Problems: make([]Problem, len(problems))}
+	for i, p := range problems {
+		resp.Problems[i] = Problem{Line: p.Line, Column: p.Column, Code: p.Code, Text: p.Text, Message: p.Message}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) verify(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var req verifyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	text, err := farmText(req.Farm, true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	farm, err := Parse(bytes.NewReader(text))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.checkAnts(w, farm) {
+		return
+	}
+
+	resp := verifyResponse{Valid: true, Ants: farm.Ants(), Turns: len(req.Moves)}
+	if err := Verify(farm, req.Moves); err != nil {
+		resp.Valid = false
+		resp.Error = err.Error()
+		var ve *VerifyError
+		if errors.As(err, &ve) {
+			resp.Turn, resp.Ant, resp.Reason = ve.Turn, ve.Ant, ve.Reason
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// readFarm parses the farm in the request body, answering the request
+// itself when that fails
+func (s *server) readFarm(w http.ResponseWriter, r *http.Request) (*Farm, bool) {
+	text, ok := s.readFarmText(w, r)
+	if !ok {
+		return nil, false
+	}
+	farm, err := Parse(bytes.NewReader(text))
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	if !s.checkAnts(w, farm) {
+		return nil, false
+	}
+	return farm, true
+}
+
+// checkAnts answers with 422 when the farm has more ants than allowed,
+// since the work and the answer grow with every ant however small the
+// request is
+func (s *server) checkAnts(w http.ResponseWriter, farm *Farm) bool {
+	if farm.Ants() > s.opts.MaxAnts {
+		writeJSON(w, http.StatusUnprocessableEntity,
+			errorResponse{Error: fmt.Sprintf("farm has %d ants, at most %d are allowed", farm.Ants(), s.opts.MaxAnts)})
+		return false
+	}
+	return true
+}
+
+// readFarmText returns the farm in the request body in the text format,
+// converting it when it was sent as JSON
+func (s *server) readFarmText(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return body, true
+	}
+	text, err := farmText(body, false)
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	return text, true
+}
+
+// farmText turns a FarmDocument, or when allowed a JSON string holding
+// the farm text, into the text format
+func farmText(data json.RawMessage, allowString bool) ([]byte, error) {
+	var text string
+	if allowString && json.Unmarshal(data, &text) == nil {
+		return []byte(text), nil
+	}
+	var doc FarmDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid farm: %v", err)
+	}
+	if err := doc.checkNames(); err != nil {
+		return nil, err
+	}
+	return []byte(doc.String()), nil
+}
+
+// readBody reads the whole request body, answering with 413 when it is
+// larger than allowed
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge,
+				errorResponse{Error: fmt.Sprintf("request body larger than %d bytes", tooLarge.Limit)})
+			return nil, false
+		}
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("error reading request: %v", err)})
+		return nil, false
+	}
+	return body, true
+}
+
+// writeError answers with 422 and where the problem is for problems with
+// the farm, and 400 for anything else
+func writeError(w http.ResponseWriter, err error) {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		writeJSON(w, http.StatusUnprocessableEntity,
+			errorResponse{Error: pe.Legacy(), Code: pe.Code, Line: pe.Line, Column: pe.Column})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
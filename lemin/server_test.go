@@ -0,0 +1,229 @@
+package lemin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// post sends body to the handler and decodes the JSON answer into v
+func post(t *testing.T, h http.Handler, target, contentType, body string, v interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("POST %s Content-Type = %q, want application/json", target, got)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("POST %s answered %q: %v", target, rec.Body.String(), err)
+	}
+	return rec.Code
+}
+
+func readTestFarm(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile("../internal/testfarms/" + name)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	return string(data)
+}
+
+const validFarmJSON = `{"ants": 4, "start": "start", "end": "end",
+	"rooms": [{"name": "start", "x": 0, "y": 0}, {"name": "room1", "x": 1, "y": 1},
+		{"name": "room2", "x": 2, "y": 2}, {"name": "end", "x": 3, "y": 3}],
+	"links": [{"from": "start", "to": "room1"}, {"from": "room1", "to": "room2"}, {"from": "room2", "to": "end"}]}`
+
+func TestServerHealth(t *testing.T) {
+	h := NewHandler(ServerOptions{})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"status":"ok"}` {
+		t.Errorf("GET /health = %d %q, want 200 {\"status\":\"ok\"}", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/solve", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /solve = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServerSolve(t *testing.T) {
+	h := NewHandler(ServerOptions{})
+	text := readTestFarm(t, "validfarm.txt")
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+	}{
+		{"text", "/solve", "text/plain", text},
+		{"no content type", "/solve", "", text},
+		{"json", "/solve", "application/json; charset=utf-8", validFarmJSON},
+		{"solver", "/solve?solver=mincost", "", text},
+		{"time budget", "/solve?timeout=1m&partial=true", "", text},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc Document
+			if code := post(t, h, tt.target, tt.contentType, tt.body, &doc); code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", code)
+			}
+			if doc.Farm.Ants != 4 || doc.Farm.Start != "start" || doc.Farm.End != "end" {
+				t.Errorf("farm = %+v, want 4 ants from start to end", doc.Farm)
+			}
+			if doc.Partial {
+				t.Error("partial = true, want a complete solution")
+			}
+			if doc.Turns != 6 || len(doc.Moves) != 6 {
+				t.Errorf("turns = %d with %d turns of moves, want 6", doc.Turns, len(doc.Moves))
+			}
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	h := NewHandler(ServerOptions{MaxBodyBytes: 200, MaxAnts: 1000})
+	text := readTestFarm(t, "validfarm.txt")
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantLine    int
+	}{
+		{"parse error", "/solve", "", "4\n##start\nstart 0 0\nbad room\n", http.StatusUnprocessableEntity, 4},
+		{"no path", "/solve", "", readTestFarm(t, "invalidfarm1.txt"), http.StatusUnprocessableEntity, 0},
+		{"unknown solver", "/solve?solver=none", "", text, http.StatusBadRequest, 0},
+		{"bad timeout", "/solve?timeout=soon", "", text, http.StatusBadRequest, 0},
+		{"bad partial", "/solve?partial=maybe", "", text, http.StatusBadRequest, 0},
+		{"bad json", "/solve", "application/json", "{", http.StatusBadRequest, 0},
+		{"bad room name", "/solve", "application/json", `{"ants": 1, "rooms": [{"name": "a b"}]}`,
+			http.StatusUnprocessableEntity, 0},
+		{"too large", "/validate", "", text + strings.Repeat("#\n", 100), http.StatusRequestEntityTooLarge, 0},
+		{"bad verify request", "/verify", "application/json", `{"moves": 1}`, http.StatusBadRequest, 0},
+		{"too many ants", "/solve", "", "20000000\n##start\na 0 0\n##end\nb 1 0\na-b\n", http.StatusUnprocessableEntity, 0},
+		{"too many ants to verify", "/verify", "application/json",
+			`{"farm": "1001\n##start\na 0 0\n##end\nb 1 0\na-b\n", "moves": []}`, http.StatusUnprocessableEntity, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp errorResponse
+			if code := post(t, h, tt.target, tt.contentType, tt.body, &resp); code != tt.wantCode {
+				t.Errorf("status = %d, want %d", code, tt.wantCode)
+			}
+			if resp.Error == "" {
+				t.Error("error is empty")
+			}
+			if resp.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d", resp.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	// No solver finishes within a nanosecond
+	h := NewHandler(ServerOptions{Timeout: 1})
+	var resp errorResponse
+	code := post(t, h, "/solve", "", readTestFarm(t, "validfarm.txt"), &resp)
+	if code != http.StatusServiceUnavailable || resp.Error != "request timed out" {
+		t.Errorf("POST /solve = %d %+v, want 503 request timed out", code, resp)
+	}
+}
+
+func TestServerValidate(t *testing.T) {
+	h := NewHandler(ServerOptions{})
+
+	var resp validateResponse
+	if code := post(t, h, "/validate", "", readTestFarm(t, "validfarm.txt"), &resp); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if !resp.Valid || len(resp.Problems) != 0 {
+		t.Errorf("valid farm = %+v, want valid with no problems", resp)
+	}
+
+	resp = validateResponse{}
+	body := "4\n##start\nstart 0 0\nbad room\n##end\nend 1 1\nstart-end\nstart-nowhere\n"
+	if code := post(t, h, "/validate", "", body, &resp); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if resp.Valid || len(resp.Problems) < 2 {
+		t.Fatalf("invalid farm = %+v, want at least 2 problems", resp)
+	}
+	if p := resp.Problems[0]; p.Line != 4 || p.Code == "" || p.Message == "" {
+		t.Errorf("first problem = %+v, want one on line 4 with a code and message", p)
+	}
+}
+
+func TestServerVerify(t *testing.T) {
+	h := NewHandler(ServerOptions{})
+	text := readTestFarm(t, "validfarm.txt")
+	farm, err := json.Marshal(text)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	moves := `["L1-room1", "L1-room2 L2-room1", "L1-end L2-room2 L3-room1",
+		"L2-end L3-room2 L4-room1", "L3-end L4-room2", "L4-end"]`
+
+	tests := []struct {
+		name  string
+		farm  string
+		moves string
+		want  verifyResponse
+	}{
+		{"text", string(farm), moves, verifyResponse{Valid: true, Ants: 4, Turns: 6}},
+		{"json", validFarmJSON, moves, verifyResponse{Valid: true, Ants: 4, Turns: 6}},
+		{"broken", string(farm), `["L1-room2"]`, verifyResponse{Ants: 4, Turns: 1, Turn: 1, Ant: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp verifyResponse
+			body := `{"farm": ` + tt.farm + `, "moves": ` + tt.moves + `}`
+			if code := post(t, h, "/verify", "application/json", body, &resp); code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", code)
+			}
+			if !tt.want.Valid && (resp.Error == "" || resp.Reason == "") {
+				t.Errorf("response = %+v, want an error and reason", resp)
+			}
+			resp.Error, resp.Reason = "", ""
+			if resp != tt.want {
+				t.Errorf("response = %+v, want %+v", resp, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	var doc FarmDocument
+	if err := json.Unmarshal([]byte(validFarmJSON), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+	farm, err := ParseDocument(doc)
+	if err != nil {
+		t.Fatalf("ParseDocument() unexpected error: %v", err)
+	}
+	want, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	if farm.Input() != want.Input() {
+		t.Errorf("Input() = %q, want %q", farm.Input(), want.Input())
+	}
+
+	doc.Rooms[1].Name = "room 1"
+	if _, err := ParseDocument(doc); err == nil {
+		t.Error("ParseDocument() with a space in a room name: expected error, got nil")
+	}
+}
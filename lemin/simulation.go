@@ -0,0 +1,108 @@
+package lemin
+
+import "lem-in/internal"
+
+// AntState is where one ant is at some turn of a Simulation: its room,
+// the path it takes and the turn it reaches the end room
+type AntState = internal.AntState
+
+// Simulation steps through a solution one turn at a time, so the position
+// of every ant can be read without parsing the Lx-y lines
+type Simulation struct {
+	sim *internal.Simulation
+	af  *internal.AntFarm
+}
+
+// Simulate starts replaying a solution for the farm, with every ant in
+// the start room before the first turn
+func (f *Farm) Simulate(s *Solution) (*Simulation, error) {
+	turns := make([][]internal.Move, len(s.Turns))
+	for i, turn := range s.Turns {
+		turns[i] = make([]internal.Move, len(turn))
+		for j, m := range turn {
+			turns[i][j] = internal.Move{Ant: m.Ant, Room: m.Room}
+		}
+	}
+	sim, err := f.af.NewSimulation(s.Paths, turns)
+	if err != nil {
+		return nil, err
+	}
+	return &Simulation{sim: sim, af: f.af}, nil
+}
+
+// Paths returns the paths of the solution, which AntState.Path indexes
+func (s *Simulation) Paths() [][]string {
+	return s.sim.Paths()
+}
+
+// Turn returns the number of turns played so far
+func (s *Simulation) Turn() int {
+	return s.sim.Turn()
+}
+
+// Turns returns the number of turns the solution takes
+func (s *Simulation) Turns() int {
+	return s.sim.Turns()
+}
+
+// Done reports whether every turn has been played
+func (s *Simulation) Done() bool {
+	return s.sim.Done()
+}
+
+// Moves returns the moves made on a turn, from 1 to Turns()
+func (s *Simulation) Moves(turn int) []Move {
+	return toMoves(s.sim.Moves(turn))
+}
+
+// Step plays the next turn and returns the moves made, or nil once every
+// turn has been played
+func (s *Simulation) Step() []Move {
+	if s.sim.Done() {
+		return nil
+	}
+	return toMoves(s.sim.Step())
+}
+
+// Seek moves straight to the state after the given turn, either forwards
+// or backwards. Turn 0 is before anything has moved
+func (s *Simulation) Seek(turn int) {
+	s.sim.Seek(turn)
+}
+
+// Ant returns the state of ant n, counting from 1. ok is false when there
+// is no such ant
+func (s *Simulation) Ant(n int) (state AntState, ok bool) {
+	return s.sim.Ant(n)
+}
+
+// Ants returns the state of every ant, in ant order
+func (s *Simulation) Ants() []AntState {
+	return s.sim.Ants()
+}
+
+// Occupied returns the ant in each room holding one, leaving out the
+// start and end rooms
+func (s *Simulation) Occupied() map[string]int {
+	return s.sim.Occupied()
+}
+
+// GridOptions sets the size of the grid DrawGrid draws on, the ant whose
+// route to highlight and whether to use ANSI colors
+type GridOptions = internal.GridOptions
+
+// DrawGrid draws the farm as it is at the current turn on a grid of
+// characters, room coordinates scaled to fit. Start and end are S and E,
+// other rooms o, or @ when holding an ant; tunnels are dots, + on a path
+// and # on the followed ant's route
+func (s *Simulation) DrawGrid(opts GridOptions) []string {
+	return s.af.DrawGrid(s.sim, opts)
+}
+
+func toMoves(moves []internal.Move) []Move {
+	turn := make([]Move, len(moves))
+	for i, m := range moves {
+		turn[i] = Move{Ant: m.Ant, Room: m.Room}
+	}
+	return turn
+}
@@ -0,0 +1,54 @@
+package lemin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	solution, err := Solve(farm)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	sim, err := farm.Simulate(solution)
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+
+	for turn := 0; !sim.Done(); turn++ {
+		if got := sim.Step(); !reflect.DeepEqual(got, solution.Turns[turn]) {
+			t.Errorf("Step() on turn %d = %v, want %v", turn+1, got, solution.Turns[turn])
+		}
+		if sim.Turn() == 3 {
+			want := map[string]int{"room1": 3, "room2": 2}
+			if got := sim.Occupied(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Occupied() after turn 3 = %v, want %v", got, want)
+			}
+		}
+	}
+	if sim.Turns() != len(solution.Turns) {
+		t.Errorf("Turns() = %d, want %d", sim.Turns(), len(solution.Turns))
+	}
+
+	sim.Seek(2)
+	ant, ok := sim.Ant(1)
+	if !ok || ant.Room != "room2" || ant.Path != 0 || ant.Arrival != 3 || ant.Arrived {
+		t.Errorf("Ant(1) after Seek(2) = %+v, want in room2 on path 0, arriving on turn 3", ant)
+	}
+	for _, n := range []int{0, farm.Ants() + 1} {
+		if _, ok := sim.Ant(n); ok {
+			t.Errorf("Ant(%d) ok = true, want false outside 1..%d", n, farm.Ants())
+		}
+	}
+	grid := sim.DrawGrid(GridOptions{Width: 4, Height: 4, Follow: 1})
+	if want := []string{"S", " @", "  *", "   E"}; !reflect.DeepEqual(grid, want) {
+		t.Errorf("DrawGrid() = %q, want %q", grid, want)
+	}
+	if got := len(sim.Ants()); got != farm.Ants() {
+		t.Errorf("Ants() returned %d ants, want %d", got, farm.Ants())
+	}
+}
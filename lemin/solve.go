@@ -0,0 +1,157 @@
+package lemin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"lem-in/internal"
+)
+
+// Move is a single ant stepping into a room
+type Move struct {
+	Ant  int
+	Room string
+}
+
+// String formats the move the way it is printed, e.g. L1-room
+func (m Move) String() string {
+	return internal.Move{Ant: m.Ant, Room: m.Room}.String()
+}
+
+// Solution is the set of paths used and the moves made on every turn. The
+// flow solvers keep the flow level that moves the ants in the fewest
+// turns, and len(Paths) is that level
+type Solution struct {
+	Paths      [][]string // Room names from start to end, shortest first
+	Ants       []int      // Number of ants sent down each path
+	Turns      [][]Move   // Moves made during each turn
+	LowerBound int        // Fewest turns any solution could possibly take
+	Warnings   []string   // Anything worth knowing about how it was found
+	Partial    bool       // Solving was stopped early; valid, but maybe not the fastest
+}
+
+// Option changes how Solve finds paths
+type Option func(*options)
+
+type options struct {
+	solver  string
+	timeout time.Duration
+	partial bool
+}
+
+// DefaultSolver is the solver used when no other is chosen
+const DefaultSolver = "edmonds-karp"
+
+// WithSolver makes Solve use the named solver, one of Solvers()
+func WithSolver(name string) Option {
+	return func(o *options) {
+		o.solver = name
+	}
+}
+
+// WithMinCost makes Solve use min-cost flow, which picks the paths with
+// the smallest total length for every number of paths, instead of
+// Edmonds-Karp
+func WithMinCost() Option {
+	return WithSolver("mincost")
+}
+
+// WithTimeout stops solving after d, on top of any deadline the context
+// passed to SolveContext has
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithPartial makes a solve that is stopped early return the best
+// solution found by then, with Partial set, instead of an error. There is
+// still an error when it is stopped before any path is found
+func WithPartial() Option {
+	return func(o *options) {
+		o.partial = true
+	}
+}
+
+// Solvers returns the names of every available solver, sorted
+func Solvers() []string {
+	return internal.SolverNames()
+}
+
+// Solve finds the paths and moves that get every ant to the end room
+func Solve(f *Farm, opts ...Option) (*Solution, error) {
+	return SolveContext(context.Background(), f, opts...)
+}
+
+// SolveContext is Solve, stopping when ctx is done. The error it returns
+// then matches ctx.Err() with errors.Is
+func SolveContext(ctx context.Context, f *Farm, opts ...Option) (*Solution, error) {
+	o := options{solver: DefaultSolver}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	solver, ok := internal.LookupSolver(o.solver)
+	if !ok {
+		return nil, fmt.Errorf("unknown solver %q, want one of %s", o.solver, strings.Join(Solvers(), ", "))
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	schedule, err := solver.Solve(ctx, f.af, f.Ants())
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Partial && !o.partial {
+		return nil, fmt.Errorf("solving stopped: %w", ctx.Err())
+	}
+	solution := &Solution{
+		Paths:      schedule.Paths,
+		Ants:       schedule.Ants,
+		Turns:      make([][]Move, len(schedule.Turns)),
+		LowerBound: schedule.LowerBound,
+		Warnings:   schedule.Warnings,
+		Partial:    schedule.Partial,
+	}
+	for i, turn := range schedule.Turns {
+		// A partial schedule is only returned once ctx is done already
+		if !schedule.Partial && ctx.Err() != nil {
+			return nil, fmt.Errorf("solving stopped: %w", ctx.Err())
+		}
+		solution.Turns[i] = toMoves(turn)
+	}
+	return solution, nil
+}
+
+// Gap returns how many turns the solution takes above the lower bound;
+// 0 means it is proven optimal
+func (s *Solution) Gap() int {
+	return len(s.Turns) - s.LowerBound
+}
+
+// Lines formats every turn as a line of Lx-y moves
+func (s *Solution) Lines() []string {
+	lines := make([]string, len(s.Turns))
+	for i, turn := range s.Turns {
+		moves := make([]string, len(turn))
+		for j, m := range turn {
+			moves[j] = m.String()
+		}
+		lines[i] = strings.Join(moves, " ")
+	}
+	return lines
+}
+
+// VerifyError describes the first turn and ant breaking a rule in Verify
+type VerifyError = internal.VerifyError
+
+// Verify checks that lines of Lx-room moves, one line per turn, are a
+// valid solution for the farm, from this or any other implementation.
+// It returns a *VerifyError for the first rule broken
+func Verify(f *Farm, lines []string) error {
+	return f.af.VerifyMoves(lines)
+}
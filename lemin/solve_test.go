@@ -0,0 +1,135 @@
+package lemin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolve(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+
+	solution, err := Solve(farm)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+
+	wantPaths := [][]string{{"start", "room1", "room2", "end"}}
+	if !reflect.DeepEqual(solution.Paths, wantPaths) {
+		t.Errorf("Solve() paths = %v, want %v", solution.Paths, wantPaths)
+	}
+	if got := solution.Turns[0]; !reflect.DeepEqual(got, []Move{{Ant: 1, Room: "room1"}}) {
+		t.Errorf("Solve() first turn = %v, want [L1-room1]", got)
+	}
+	wantLines := []string{
+		"L1-room1",
+		"L1-room2 L2-room1",
+		"L1-end L2-room2 L3-room1",
+		"L2-end L3-room2 L4-room1",
+		"L3-end L4-room2",
+		"L4-end",
+	}
+	if got := solution.Lines(); !reflect.DeepEqual(got, wantLines) {
+		t.Errorf("Lines() = %v, want %v", got, wantLines)
+	}
+	if solution.LowerBound != 6 || solution.Gap() != 0 {
+		t.Errorf("Solve() lower bound = %v, gap = %v, want 6 and 0", solution.LowerBound, solution.Gap())
+	}
+}
+
+func TestVerify(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	solution, err := Solve(farm)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	if err := Verify(farm, solution.Lines()); err != nil {
+		t.Errorf("Verify() rejected our own solution: %v", err)
+	}
+
+	var ve *VerifyError
+	if err := Verify(farm, []string{"L1-room2"}); !errors.As(err, &ve) || ve.Turn != 1 || ve.Ant != 1 {
+		t.Errorf("Verify() = %v, want turn 1 ant 1", err)
+	}
+}
+
+func TestSolveWithMinCost(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/regression/example.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	solution, err := Solve(farm, WithMinCost())
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+	if err := Verify(farm, solution.Lines()); err != nil {
+		t.Errorf("Verify() rejected the min-cost solution: %v", err)
+	}
+	if got := len(solution.Turns); got > 8 {
+		t.Errorf("Solve() took %d turns, want at most 8", got)
+	}
+}
+
+func TestSolveUnknownSolver(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	if _, err := Solve(farm, WithSolver("nope")); err == nil {
+		t.Error("Solve() expected error for an unknown solver")
+	}
+	for _, name := range Solvers() {
+		if _, err := Solve(farm, WithSolver(name)); err != nil {
+			t.Errorf("Solve() with %s: %v", name, err)
+		}
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	farm, err := ParseFile("../internal/testfarms/validfarm.txt")
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, name := range Solvers() {
+		// Nothing is found before the first check, so even a partial
+		// answer is an error
+		for _, opts := range [][]Option{{WithSolver(name)}, {WithSolver(name), WithPartial()}} {
+			if _, err := SolveContext(ctx, farm, opts...); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: SolveContext() with a canceled context error = %v, want context.Canceled", name, err)
+			}
+		}
+	}
+
+	solution, err := Solve(farm, WithTimeout(time.Minute), WithPartial())
+	if err != nil {
+		t.Fatalf("Solve() with time to spare unexpected error: %v", err)
+	}
+	if solution.Partial || len(solution.Turns) != 6 {
+		t.Errorf("Solve() with time to spare = partial %v in %d turns, want complete in 6", solution.Partial, len(solution.Turns))
+	}
+
+	// The path is found at once, writing out millions of turns is what
+	// runs out of time
+	huge, err := Parse(strings.NewReader("20000000\n##start\na 0 0\n##end\nb 1 0\na-b\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	start := time.Now()
+	if _, err := Solve(huge, WithTimeout(50*time.Millisecond), WithPartial()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Solve() of 20000000 ants in 50ms error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Solve() of 20000000 ants in 50ms took %v", elapsed)
+	}
+}